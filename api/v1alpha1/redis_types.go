@@ -17,29 +17,151 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"strings"
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// KindDeployment runs Redis pods as a Deployment without persistent storage
+	KindDeployment = "deployment"
+	// KindStatefulSet runs Redis pods as a StatefulSet with a PVC per pod
+	KindStatefulSet = "statefulset"
+)
+
+const (
+	// ModeReplication runs a master with replicas, optionally monitored by Sentinel
+	ModeReplication = "replication"
+	// ModeCluster runs Redis Cluster sharded between several masters
+	ModeCluster = "cluster"
 )
 
 // RedisSpec defines the desired state of Redis
+// +kubebuilder:validation:XValidation:rule="self.mode == 'cluster' || self.replica.count == 0 || self.master.count > 0",message="replicas require at least one master"
+// +kubebuilder:validation:XValidation:rule="!self.sentinel.enabled || self.mode != 'cluster'",message="Sentinel can not be used in cluster mode"
+// +kubebuilder:validation:XValidation:rule="!self.sentinel.enabled || self.sentinel.quorum <= self.sentinel.count",message="sentinel.quorum must not be greater than sentinel.count"
+// +kubebuilder:validation:XValidation:rule="self.common.auth.enabled || !has(self.common.auth.existingSecret)",message="existingSecret can not be used when auth.enabled is false"
+// +kubebuilder:validation:XValidation:rule="!has(self.restore) || self.mode != 'cluster'",message="restore can not be used in cluster mode"
+// +kubebuilder:validation:XValidation:rule="!has(self.restore) || (has(oldSelf.restore) && self.restore == oldSelf.restore)",message="restore can only be set when Redis is created"
+// +kubebuilder:validation:XValidation:rule="!has(self.restore) || self.master.kind == 'statefulset'",message="restore requires master kind statefulset, which keeps the restored dataset when master pods are restarted"
+// +kubebuilder:validation:XValidation:rule="!has(self.cloneFrom) || (has(oldSelf.cloneFrom) && self.cloneFrom == oldSelf.cloneFrom)",message="cloneFrom can only be set when Redis is created"
+// +kubebuilder:validation:XValidation:rule="!has(self.cloneFrom) || !has(self.restore)",message="cloneFrom and restore can not be used together"
+// +kubebuilder:validation:XValidation:rule="!has(self.cloneFrom) || (self.mode != 'cluster' && !self.sentinel.enabled)",message="cloneFrom can not be used in cluster mode or with Sentinel"
+// +kubebuilder:validation:XValidation:rule="!has(self.cloneFrom) || self.master.kind == 'statefulset'",message="cloneFrom requires master kind statefulset, which keeps the cloned dataset when master pods are restarted"
+// +kubebuilder:validation:XValidation:rule="!has(self.externalMaster) || (has(oldSelf.externalMaster) && self.externalMaster == oldSelf.externalMaster)",message="externalMaster can only be set when Redis is created"
+// +kubebuilder:validation:XValidation:rule="!has(self.externalMaster) || (!has(self.restore) && !has(self.cloneFrom))",message="externalMaster can not be used together with restore or cloneFrom"
+// +kubebuilder:validation:XValidation:rule="!has(self.externalMaster) || (self.mode != 'cluster' && !self.sentinel.enabled)",message="externalMaster can not be used in cluster mode or with Sentinel"
+// +kubebuilder:validation:XValidation:rule="!has(self.externalMaster) || self.master.kind == 'statefulset'",message="externalMaster requires master kind statefulset, which keeps the replicated dataset when master pods are restarted"
+// +kubebuilder:validation:XValidation:rule="!has(self.externalMaster) || self.externalMaster.tls == self.common.tls.enabled",message="externalMaster.tls must match common.tls.enabled, master replicates with the certificate and CA of common.tls"
 type RedisSpec struct {
+	// Redis deployment mode, either 'replication' or 'cluster'. Defaults to 'replication'
+	// +kubebuilder:default:=replication
+	// +kubebuilder:validation:Enum=replication;cluster
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="mode is immutable"
+	Mode string `json:"mode,omitempty"`
 	// Common values for Redis deployment
+	// +kubebuilder:default:={}
 	Common RedisCommonSpec `json:"common,omitempty"`
 	// Redis master parameters
+	// +kubebuilder:default:={}
 	Master RedisMasterSpec `json:"master,omitempty"`
 	// Redis replica parameters
+	// +kubebuilder:default:={}
 	Replica RedisReplicaSpec `json:"replica,omitempty"`
+	// Redis Sentinel parameters
+	// +kubebuilder:default:={}
+	Sentinel RedisSentinelSpec `json:"sentinel,omitempty"`
+	// Redis Cluster parameters. Used only with 'cluster' mode
+	// +kubebuilder:default:={}
+	Cluster RedisClusterSpec `json:"cluster,omitempty"`
+	// RDB file master pods are bootstrapped from. Not supported in cluster mode
+	Restore *RedisRestoreSpec `json:"restore,omitempty"`
+	// Operator-managed Redis master pods replicate from until they are in sync. Not supported in cluster mode
+	CloneFrom *RedisCloneSource `json:"cloneFrom,omitempty"`
+	// Redis server outside of the operator master pods replicate from until they are promoted. Not supported in cluster mode
+	ExternalMaster *RedisExternalMasterSpec `json:"externalMaster,omitempty"`
+}
+
+// RedisExternalMasterSpec is a Redis server not managed by the operator whose
+// dataset is migrated into the operator
+type RedisExternalMasterSpec struct {
+	// Host name or IP address of the server
+	// +kubebuilder:validation:MinLength=1
+	Host string `json:"host"`
+	// Port of the server. Defaults to 6379
+	// +kubebuilder:default:=6379
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port,omitempty"`
+	// Name of the secret in the same namespace with REDIS_PASSWORD of the server. No authentication if empty
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+	// Connect to the server over TLS. Requires common.tls.enabled, whose CA has to trust the certificate of the server
+	TLS bool `json:"tls,omitempty"`
+}
+
+// RedisCloneSource is an operator-managed Redis in replication mode a new Redis is cloned from
+type RedisCloneSource struct {
+	// Name of the source Redis
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Namespace of the source Redis. Defaults to the namespace of the clone.
+	// Source in another namespace has to list the namespace of the clone in its clone-namespaces annotation
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// RedisRestoreSpec is an RDB file in S3-compatible storage, usually taken by RedisBackup
+type RedisRestoreSpec struct {
+	// URL of the storage, e.g. 'https://s3.eu-central-1.amazonaws.com' or 'http://minio.minio:9000'
+	// +kubebuilder:validation:Pattern=`^https?://[^/]+/?$`
+	Endpoint string `json:"endpoint"`
+	// Region of the bucket. Defaults to 'us-east-1'
+	// +kubebuilder:default:=us-east-1
+	Region string `json:"region,omitempty"`
+	// Name of the secret in the same namespace with AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY
+	// +kubebuilder:validation:MinLength=1
+	CredentialsSecret string `json:"credentialsSecret"`
+	// Location of the RDB file as recorded in RedisBackup status, e.g. 's3://backups/redis/default/cache/daily.rdb'
+	// +kubebuilder:validation:Pattern=`^s3://[^/]+/.+$`
+	Location string `json:"location"`
+	// Checksum of the RDB file as recorded in RedisBackup status, 'sha256:<hex>'. Restore fails if the downloaded file differs
+	// +kubebuilder:validation:Pattern=`^sha256:[0-9a-f]{64}$`
+	Checksum string `json:"checksum,omitempty"`
 }
 
 type RedisCommonSpec struct {
 	// Redis image parameters
 	// +kubebuilder:default={}
 	Image RedisImageSpec `json:"image,omitempty"`
-	// Storage class for Redis PVCs. Defaults to standard
-	// +kubebuilder:default:="standard"
+	// Storage class for Redis PVCs. Defaults to the default StorageClass of the cluster
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="storageClass is immutable"
 	StorageClass string `json:"storageClass,omitempty"`
 	// Redis Authentication configuration
+	// +kubebuilder:default:={}
 	Auth RedisAuthSpec `json:"auth,omitempty"`
+	// TLS configuration of client, replication, Sentinel and cluster bus traffic
+	// +kubebuilder:default:={}
+	TLS RedisTLSSpec `json:"tls,omitempty"`
+	// redis.conf directives of all Redis pods, e.g. 'maxmemory-policy: allkeys-lru'
+	Config RedisConfig `json:"config,omitempty"`
+	// Percentage of the memory limit of Redis container used as maxmemory
+	// unless maxmemory is set in config. Defaults to 75
+	// +kubebuilder:default:=75
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	MaxMemoryPercent int32 `json:"maxmemoryPercent,omitempty"`
+	// PodDisruptionBudget spanning master and replica pods, so a master and its replicas
+	// are not evicted at once. Not created for a single pod or in cluster mode. Budgets of
+	// master and replica pods are created instead when it is disabled
+	// +kubebuilder:default:={}
+	PodDisruptionBudget RedisPodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
+	// Probes of Redis containers
+	// +kubebuilder:default:={}
+	Probes RedisProbesSpec `json:"probes,omitempty"`
 }
 
 type RedisImageSpec struct {
@@ -57,62 +179,622 @@ type RedisImageSpec struct {
 }
 
 type RedisAuthSpec struct {
-	// Enable password authentication
+	// Enable password authentication. Without it Redis accepts connections
+	// without password, use it only for development and testing
 	// +kubebuilder:default:=true
-	Enabled bool `json:"enabled,omitempty"`
+	Enabled *bool `json:"enabled,omitempty"`
 	// The name of an existing secret with Redis credentials. Should contain REDIS_PASSWORD key
 	ExistingSecret string `json:"existingSecret,omitempty"`
+	// Period after which the generated password is rotated, e.g. '720h'.
+	// Rotation can also be requested with cache.assignment.yazio.com/rotate-password annotation
+	RotationPeriod *metav1.Duration `json:"rotationPeriod,omitempty"`
 }
 
+// RedisTLSSpec defines TLS configuration of Redis
+// +kubebuilder:validation:XValidation:rule="self.enabled || !has(self.existingSecret)",message="existingSecret can not be used when tls.enabled is false"
+type RedisTLSSpec struct {
+	// Serve Redis and Sentinel over TLS only, plain TCP ports are disabled
+	Enabled bool `json:"enabled,omitempty"`
+	// The name of an existing secret with tls.crt, tls.key and ca.crt keys. When empty
+	// the operator generates a self-signed CA and a serving certificate and renews it before expiry
+	ExistingSecret string `json:"existingSecret,omitempty"`
+	// Require clients to authenticate with a certificate signed by the CA
+	AuthClients bool `json:"authClients,omitempty"`
+	// Validity of the generated serving certificate. Defaults to 2160h
+	CertificateDuration *metav1.Duration `json:"certificateDuration,omitempty"`
+	// Time before expiry the generated serving certificate is renewed. Defaults to 720h
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+}
+
+// RedisConfig holds redis.conf directives by name. Directives the operator
+// sets itself can not be overridden.
+// +kubebuilder:validation:MaxProperties=100
+// +kubebuilder:validation:XValidation:rule="self.all(k, !(k in ['port', 'tls-port', 'bind', 'replicaof', 'slaveof', 'masterauth', 'masteruser', 'requirepass', 'aclfile', 'include', 'dir', 'cluster-enabled', 'cluster-config-file', 'cluster-node-timeout', 'tls-cert-file', 'tls-key-file', 'tls-ca-cert-file', 'tls-auth-clients', 'tls-replication', 'tls-cluster']))",message="config must not contain directives managed by the operator"
+type RedisConfig map[string]string
+
+// OperatorConfigDirectives are redis.conf directives set by the operator
+var OperatorConfigDirectives = []string{
+	"port", "tls-port", "bind", "replicaof", "slaveof", "masterauth", "masteruser", "requirepass",
+	"aclfile", "include", "dir", "cluster-enabled", "cluster-config-file", "cluster-node-timeout",
+	"tls-cert-file", "tls-key-file", "tls-ca-cert-file", "tls-auth-clients", "tls-replication", "tls-cluster",
+}
+
+// IsOperatorConfigDirective checks if redis.conf directive is set by the operator
+// and can not be overridden by config. Directive names are case insensitive.
+func IsOperatorConfigDirective(name string) bool {
+	for _, directive := range OperatorConfigDirectives {
+		if strings.EqualFold(name, directive) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedisProbesSpec defines probes of Redis containers. All probes run an
+// authenticated PING, readiness of replicas also requires the link to master
+// to be up and the dataset to be loaded
+type RedisProbesSpec struct {
+	// Probe restarting Redis which stops answering. Defaults to 10s period,
+	// 5s timeout and 5 failures
+	Liveness RedisProbeSpec `json:"liveness,omitempty"`
+	// Probe removing Redis from Services while it is loading data or replica
+	// is disconnected from master. Defaults to 5s period, 3s timeout and 3 failures
+	Readiness RedisProbeSpec `json:"readiness,omitempty"`
+	// Probe giving Redis time to load the dataset on start. Defaults to 5s
+	// period, 5s timeout and 60 failures
+	Startup RedisProbeSpec `json:"startup,omitempty"`
+}
+
+// RedisProbeSpec holds probe thresholds, unset values use probe defaults
+type RedisProbeSpec struct {
+	// Seconds after container start before the probe runs
+	// +kubebuilder:validation:Minimum=0
+	InitialDelaySeconds int32 `json:"initialDelaySeconds,omitempty"`
+	// How often the probe runs, in seconds
+	// +kubebuilder:validation:Minimum=0
+	PeriodSeconds int32 `json:"periodSeconds,omitempty"`
+	// Seconds after which the probe times out
+	// +kubebuilder:validation:Minimum=0
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+	// Consecutive failures after which the probe fails
+	// +kubebuilder:validation:Minimum=0
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+}
+
+// RotatePasswordAnnotation requests password rotation when its value changes
+const RotatePasswordAnnotation = "cache.assignment.yazio.com/rotate-password"
+
+// PromoteAnnotation set to 'true' promotes master pods replicating from the
+// external master to normal masters once they are in sync
+const PromoteAnnotation = "cache.assignment.yazio.com/promote"
+
+// CloneNamespacesAnnotation of a Redis lists namespaces, separated by commas,
+// whose Redis may clone it. Redis in its own namespace may always clone it.
+const CloneNamespacesAnnotation = "cache.assignment.yazio.com/clone-namespaces"
+
 type RedisMasterSpec struct {
+	// Scheduling of the pods
+	RedisSchedulingSpec `json:",inline"`
 	// Number of Redis pods
 	// +kubebuilder:default:=1
 	Count int32 `json:"count,omitempty"`
 	// Type of Redis deployment. Defaults to 'deployment'
 	// +kubebuilder:default:=deployment
+	// +kubebuilder:validation:Enum=deployment;statefulset
 	Kind string `json:"kind,omitempty"`
-	// Redis PVC configuration
-	//Persistence RedisMasterPersistence `json:"persistence,omitempty"`
+	// Redis PVC configuration. Used only with 'statefulset' kind
+	// +kubebuilder:default:={}
+	Persistence RedisPersistenceSpec `json:"persistence,omitempty"`
+	// redis.conf directives of master pods, override common.config
+	Config RedisConfig `json:"config,omitempty"`
+	// Compute resources of Redis container of master pods
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// PodDisruptionBudget of master pods. Created only when common.podDisruptionBudget is disabled,
+	// and not for a single pod, which it would block from eviction
+	// +kubebuilder:default:={}
+	PodDisruptionBudget RedisPodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
 }
 
-// type RedisMasterPersistence struct {
-// 	// Enable persisten storage
-// 	// +kubebuilder:default:=false
-// 	Enabled bool `json:"enabled,omitempty"`
-// 	// PVC size
-// 	Size string `json:"size,omitempty"`
-// }
-
 type RedisReplicaSpec struct {
+	// Scheduling of the pods
+	RedisSchedulingSpec `json:",inline"`
 	// Number of Redis pods
 	// +kubebuilder:default:=0
 	Count int32 `json:"count,omitempty"`
 	// Type of Redis deployment. Defaults to 'deployment'
 	// +kubebuilder:default:=deployment
+	// +kubebuilder:validation:Enum=deployment;statefulset
 	Kind string `json:"kind,omitempty"`
-	// Redis PVC configuration
-	//Persistence RedisReplicaPersistence `json:"persistence,omitempty"`
+	// Redis PVC configuration. Used only with 'statefulset' kind
+	// +kubebuilder:default:={}
+	Persistence RedisPersistenceSpec `json:"persistence,omitempty"`
+	// redis.conf directives of replica pods, override common.config
+	Config RedisConfig `json:"config,omitempty"`
+	// Compute resources of Redis container of replica pods
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// PodDisruptionBudget of replica pods. Created only when common.podDisruptionBudget is disabled,
+	// and not for a single pod, which it would block from eviction
+	// +kubebuilder:default:={}
+	PodDisruptionBudget RedisPodDisruptionBudgetSpec `json:"podDisruptionBudget,omitempty"`
 }
 
-// type RedisReplicaPersistence struct {
-// 	// Enable persisten storage
-// 	// +kubebuilder:default:=false
-// 	Enabled bool `json:"enabled,omitempty"`
-// 	// PVC size
-// 	Size string `json:"size,omitempty"`
-// }
+// RedisSchedulingSpec defines where pods of a Redis role are scheduled. Without
+// podAntiAffinity pods prefer nodes and zones without other pods of the Redis
+type RedisSchedulingSpec struct {
+	// Node labels pods are scheduled on
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Scheduling constraints of the pods
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// Tolerations of the pods
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// Constraints spreading the pods across topology domains
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	// PriorityClass of the pods
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// RuntimeClass the pods are run with
+	RuntimeClassName *string `json:"runtimeClassName,omitempty"`
+}
+
+// RedisPodDisruptionBudgetSpec limits voluntary disruptions of pods of a Redis role
+// +kubebuilder:validation:XValidation:rule="!(has(self.minAvailable) && has(self.maxUnavailable))",message="minAvailable and maxUnavailable are mutually exclusive"
+type RedisPodDisruptionBudgetSpec struct {
+	// Create PodDisruptionBudget
+	// +kubebuilder:default:=true
+	Enabled *bool `json:"enabled,omitempty"`
+	// Number or percentage of pods that must stay available during eviction
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+	// Number or percentage of pods that can be unavailable during eviction.
+	// Defaults to 1 unless minAvailable is set
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+type RedisPersistenceSpec struct {
+	// PVC size. Defaults to 8Gi
+	// +kubebuilder:default:="8Gi"
+	Size resource.Quantity `json:"size,omitempty"`
+	// PVC access modes. Defaults to ReadWriteOnce
+	// +kubebuilder:default:={ReadWriteOnce}
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+}
+
+type RedisSentinelSpec struct {
+	// Scheduling of the pods
+	RedisSchedulingSpec `json:",inline"`
+	// Deploy Redis Sentinel to monitor master and promote a replica on failure
+	// +kubebuilder:default:=false
+	Enabled bool `json:"enabled,omitempty"`
+	// Number of Sentinel pods
+	// +kubebuilder:default:=3
+	Count int32 `json:"count,omitempty"`
+	// Number of Sentinels that need to agree about master failure
+	// +kubebuilder:default:=2
+	Quorum int32 `json:"quorum,omitempty"`
+	// Name of the master set monitored by Sentinel
+	// +kubebuilder:default:="mymaster"
+	MasterSet string `json:"masterSet,omitempty"`
+	// Time in milliseconds master should be unreachable to be considered down
+	// +kubebuilder:default:=5000
+	DownAfterMilliseconds int32 `json:"downAfterMilliseconds,omitempty"`
+	// Failover timeout in milliseconds
+	// +kubebuilder:default:=10000
+	FailoverTimeout int32 `json:"failoverTimeout,omitempty"`
+}
+
+type RedisClusterSpec struct {
+	// Scheduling of the pods
+	RedisSchedulingSpec `json:",inline"`
+	// Number of shards, each shard is a master with its replicas
+	// +kubebuilder:default:=3
+	// +kubebuilder:validation:Minimum=1
+	Shards int32 `json:"shards,omitempty"`
+	// Number of replicas of every shard master
+	// +kubebuilder:default:=1
+	// +kubebuilder:validation:Minimum=0
+	ReplicasPerShard int32 `json:"replicasPerShard,omitempty"`
+	// Time in milliseconds a node should be unreachable to be considered failing
+	// +kubebuilder:default:=5000
+	NodeTimeout int32 `json:"nodeTimeout,omitempty"`
+	// Redis PVC configuration of cluster nodes
+	// +kubebuilder:default:={}
+	Persistence RedisPersistenceSpec `json:"persistence,omitempty"`
+	// redis.conf directives of cluster nodes, override common.config
+	Config RedisConfig `json:"config,omitempty"`
+	// Compute resources of Redis container of cluster nodes
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
 
 // RedisStatus defines the observed state of Redis
 type RedisStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
-	Conditions []metav1.Condition `json:"conditions"`
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Generation of the spec the status was computed for
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Number of reachable pods running as master. In cluster mode number of shards with master
+	ReadyMasters int32 `json:"readyMasters"`
+	// Number of reachable replicas in sync with their master
+	ReadyReplicas int32 `json:"readyReplicas"`
+	// Pod currently running as master. Empty in cluster mode
+	CurrentMaster string `json:"currentMaster,omitempty"`
+	// Addresses clients connect to
+	Endpoints *RedisEndpoints `json:"endpoints,omitempty"`
+	// Workload kind migrations, one per Redis component
+	Migrations []RedisMigrationStatus `json:"migrations,omitempty"`
+	// Redis Cluster state. Set only in 'cluster' mode
+	Cluster *RedisClusterStatus `json:"cluster,omitempty"`
+	// State of the generated password
+	Auth *RedisAuthStatus `json:"auth,omitempty"`
+	// State of the serving certificate. Set only when TLS is enabled
+	TLS *RedisTLSStatus `json:"tls,omitempty"`
+	// State of redis.conf directives in Redis pods. Set only when config is used
+	Config *RedisConfigStatus `json:"config,omitempty"`
+	// Progress of restore from RDB file. Set only when restore is used
+	Restore *RedisRestoreStatus `json:"restore,omitempty"`
+	// Progress of cloning from the source Redis. Set only when cloneFrom is used
+	Clone *RedisCloneStatus `json:"clone,omitempty"`
+	// Replication from the external master. Set only when externalMaster is used
+	ExternalMaster *RedisExternalMasterStatus `json:"externalMaster,omitempty"`
+}
+
+// RedisExternalMasterPhase is a step of migration from the external master
+// +kubebuilder:validation:Enum=Syncing;InSync;Promoted
+type RedisExternalMasterPhase string
+
+const (
+	// Master pods connect to the external master or load its dataset
+	ExternalMasterPhaseSyncing RedisExternalMasterPhase = "Syncing"
+	// Master pods stream writes of the external master and can be promoted
+	ExternalMasterPhaseInSync RedisExternalMasterPhase = "InSync"
+	// Master pods detached from the external master and serve as normal masters
+	ExternalMasterPhasePromoted RedisExternalMasterPhase = "Promoted"
+)
+
+type RedisExternalMasterStatus struct {
+	// Current phase
+	Phase RedisExternalMasterPhase `json:"phase"`
+	// Details of the current phase
+	Message string `json:"message,omitempty"`
+	// Percentage of the dataset transferred by the full sync in progress
+	SyncProgress string `json:"syncProgress,omitempty"`
+	// Bytes of the replication stream of the external master not processed by master pods yet. Unknown if the operator can not reach the external master
+	LagBytes *int64 `json:"lagBytes,omitempty"`
+	// Seconds since master pods last received data from the external master
+	LastIOSecondsAgo *int64 `json:"lastIOSecondsAgo,omitempty"`
+	// Time master pods were promoted
+	PromotionTime *metav1.Time `json:"promotionTime,omitempty"`
+}
+
+// RedisClonePhase is a step of cloning from the source Redis
+// +kubebuilder:validation:Enum=Syncing;Completed;Failed
+type RedisClonePhase string
+
+const (
+	// Master pods replicate from the source Redis
+	ClonePhaseSyncing RedisClonePhase = "Syncing"
+	// Master pods detached from the source Redis in sync and serve the cloned dataset
+	ClonePhaseCompleted RedisClonePhase = "Completed"
+	// Source Redis can not be cloned, cloning is retried
+	ClonePhaseFailed RedisClonePhase = "Failed"
+)
+
+type RedisCloneStatus struct {
+	// Current clone phase
+	Phase RedisClonePhase `json:"phase"`
+	// Details of the current phase
+	Message string `json:"message,omitempty"`
+	// Time the cloning was started
+	StartTime metav1.Time `json:"startTime"`
+	// Time master pods detached from the source Redis
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// RedisRestorePhase is a step of restore from RDB file
+// +kubebuilder:validation:Enum=Downloading;Loading;Completed;Failed
+type RedisRestorePhase string
+
+const (
+	// Init container of master pods downloads and verifies the RDB file
+	RestorePhaseDownloading RedisRestorePhase = "Downloading"
+	// Master loads the RDB file, replicas are started once it is loaded
+	RestorePhaseLoading RedisRestorePhase = "Loading"
+	// Master serves the restored dataset
+	RestorePhaseCompleted RedisRestorePhase = "Completed"
+	// Download or verification failed, the init container retries it
+	RestorePhaseFailed RedisRestorePhase = "Failed"
+)
+
+type RedisRestoreStatus struct {
+	// Current restore phase
+	Phase RedisRestorePhase `json:"phase"`
+	// Details of the current phase
+	Message string `json:"message,omitempty"`
+	// Time the restore was started
+	StartTime metav1.Time `json:"startTime"`
+	// Time the restore was completed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// Condition types of Redis
+const (
+	// All desired pods are ready and replication is healthy
+	ConditionReady = "Ready"
+	// Operator is changing the deployment, e.g. rolling out workloads, migrating or resharding
+	ConditionProgressing = "Progressing"
+	// Redis is not able to reach desired state or reconciliation fails
+	ConditionDegraded = "Degraded"
+	// Replicas are connected to their master and in sync
+	ConditionReplicationHealthy = "ReplicationHealthy"
+	// Password authentication is disabled and Redis accepts any client
+	ConditionAuthDisabled = "AuthDisabled"
+	// maxmemory set in config exceeds the memory limit, Redis may be OOM-killed
+	ConditionMaxMemoryExceedsLimit = "MaxMemoryExceedsLimit"
+)
+
+type RedisEndpoints struct {
+	// Address of master Service
+	Master string `json:"master,omitempty"`
+	// Address of replica Service
+	Replica string `json:"replica,omitempty"`
+	// Address of Sentinel Service
+	Sentinel string `json:"sentinel,omitempty"`
+	// Address of Redis Cluster Service
+	Cluster string `json:"cluster,omitempty"`
+}
+
+type RedisClusterStatus struct {
+	// Cluster state reported by CLUSTER INFO, 'ok' or 'fail'
+	State string `json:"state,omitempty"`
+	// Number of hash slots assigned to shard masters
+	SlotsAssigned int32 `json:"slotsAssigned"`
+	// Slot ownership of every shard
+	Shards []RedisClusterShardStatus `json:"shards,omitempty"`
+	// Progress of resharding after shard count change
+	Resharding *RedisReshardingStatus `json:"resharding,omitempty"`
+}
+
+// RedisReshardingStep is a step of online resharding
+// +kubebuilder:validation:Enum=MigratingSlots;RemovingShards
+type RedisReshardingStep string
+
+const (
+	// Slots are moved between shard masters to match new shard count
+	ReshardingStepMigratingSlots RedisReshardingStep = "MigratingSlots"
+	// Drained shards are being removed from the cluster
+	ReshardingStepRemovingShards RedisReshardingStep = "RemovingShards"
+)
+
+type RedisReshardingStatus struct {
+	// Current resharding step
+	Step RedisReshardingStep `json:"step"`
+	// Number of shards before resharding
+	FromShards int32 `json:"fromShards"`
+	// Number of shards after resharding
+	ToShards int32 `json:"toShards"`
+	// Number of slots that have to be moved
+	SlotsToMove int32 `json:"slotsToMove"`
+	// Number of slots already moved
+	SlotsMoved int32 `json:"slotsMoved"`
+	// Number of keys already moved
+	KeysMoved int64 `json:"keysMoved"`
+	// Slot being migrated at the moment
+	CurrentSlot *int32 `json:"currentSlot,omitempty"`
+	// Node ID of the master the current slot is migrated from
+	SourceNodeID string `json:"sourceNodeID,omitempty"`
+	// Node ID of the master the current slot is migrated to
+	TargetNodeID string `json:"targetNodeID,omitempty"`
+	// Time resharding was started
+	StartTime metav1.Time `json:"startTime"`
+}
+
+type RedisClusterShardStatus struct {
+	// Shard index
+	Index int32 `json:"index"`
+	// Name of the pod currently serving as shard master
+	Master string `json:"master,omitempty"`
+	// Cluster node ID of the shard master
+	NodeID string `json:"nodeID,omitempty"`
+	// Hash slot ranges owned by the shard, e.g. "0-5460"
+	Slots string `json:"slots,omitempty"`
+	// Number of replicas attached to the shard master
+	Replicas int32 `json:"replicas"`
+}
+
+type RedisConfigStatus struct {
+	// Directives applied to running pods with CONFIG SET by the last config change
+	HotApplied []string `json:"hotApplied,omitempty"`
+	// Directives which take effect once Redis pods are restarted
+	PendingRestart []string `json:"pendingRestart,omitempty"`
+}
+
+type RedisTLSStatus struct {
+	// Serial number of the certificate served by Redis pods
+	SerialNumber string `json:"serialNumber,omitempty"`
+	// Expiry time of the serving certificate
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+}
+
+type RedisAuthStatus struct {
+	// Time the password was last rotated
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+	// Value of rotate-password annotation the last rotation was requested with
+	LastRotationRequest string `json:"lastRotationRequest,omitempty"`
+	// Progress of password rotation
+	Rotation *RedisPasswordRotationStatus `json:"rotation,omitempty"`
+}
+
+// RedisPasswordRotationPhase is a step of password rotation
+// +kubebuilder:validation:Enum=AddingPassword;RestartingReplicas;RevokingPassword
+type RedisPasswordRotationPhase string
+
+const (
+	// New password is accepted by Redis pods next to the old one
+	PasswordRotationPhaseAddingPassword RedisPasswordRotationPhase = "AddingPassword"
+	// Secret holds the new password, replicas are restarted to use it for master connection
+	PasswordRotationPhaseRestartingReplicas RedisPasswordRotationPhase = "RestartingReplicas"
+	// Old password is removed from Redis pods
+	PasswordRotationPhaseRevokingPassword RedisPasswordRotationPhase = "RevokingPassword"
+)
+
+type RedisPasswordRotationStatus struct {
+	// Current rotation phase
+	Phase RedisPasswordRotationPhase `json:"phase"`
+	// Details of the current phase
+	Message string `json:"message,omitempty"`
+	// Time the rotation was started
+	StartTime metav1.Time `json:"startTime"`
+}
+
+// RedisMigrationPhase is a step of migration between workload kinds
+// +kubebuilder:validation:Enum=CreatingTarget;Syncing;SwitchingService;RemovingSource;Completed
+type RedisMigrationPhase string
+
+const (
+	// Target workload is created and waits for its pods to become ready
+	MigrationPhaseCreatingTarget RedisMigrationPhase = "CreatingTarget"
+	// Target pods replicate data from the source master
+	MigrationPhaseSyncing RedisMigrationPhase = "Syncing"
+	// Component Service is switched to target pods
+	MigrationPhaseSwitchingService RedisMigrationPhase = "SwitchingService"
+	// Source workload is being deleted
+	MigrationPhaseRemovingSource RedisMigrationPhase = "RemovingSource"
+	MigrationPhaseCompleted      RedisMigrationPhase = "Completed"
+)
+
+type RedisMigrationStatus struct {
+	// Redis component, either redis-master or redis-replica
+	Component string `json:"component"`
+	// Workload kind the component is migrated from
+	From string `json:"from"`
+	// Workload kind the component is migrated to
+	To string `json:"to"`
+	// Current migration phase
+	Phase RedisMigrationPhase `json:"phase"`
+	// Details of the current phase
+	Message string `json:"message,omitempty"`
+	// Time the migration was started
+	StartTime metav1.Time `json:"startTime"`
+	// Time the migration was completed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// IsEnabled checks if Redis requires password. Authentication is enabled unless disabled explicitly
+func (auth *RedisAuthSpec) IsEnabled() bool {
+	return auth.Enabled == nil || *auth.Enabled
+}
+
+// IsEnabled checks if PodDisruptionBudget is created. It is enabled unless disabled explicitly
+func (pdb *RedisPodDisruptionBudgetSpec) IsEnabled() bool {
+	return pdb.Enabled == nil || *pdb.Enabled
+}
+
+// DefaultMaxMemoryPercent is the percentage of the memory limit used as maxmemory
+const DefaultMaxMemoryPercent = 75
+
+// GetMaxMemoryPercent returns percentage of the memory limit used as maxmemory
+func (common *RedisCommonSpec) GetMaxMemoryPercent() int32 {
+	if common.MaxMemoryPercent == 0 {
+		return DefaultMaxMemoryPercent
+	}
+	return common.MaxMemoryPercent
+}
+
+// Certificate lifetime defaults of generated TLS certificates
+const (
+	DefaultCertificateDuration = 90 * 24 * time.Hour
+	DefaultRenewBefore         = 30 * 24 * time.Hour
+)
+
+// GetCertificateDuration returns validity of the generated serving certificate
+func (tls *RedisTLSSpec) GetCertificateDuration() time.Duration {
+	if tls.CertificateDuration == nil {
+		return DefaultCertificateDuration
+	}
+	return tls.CertificateDuration.Duration
+}
+
+// GetRenewBefore returns time before expiry the generated certificate is renewed
+func (tls *RedisTLSSpec) GetRenewBefore() time.Duration {
+	if tls.RenewBefore == nil {
+		return DefaultRenewBefore
+	}
+	return tls.RenewBefore.Duration
+}
+
+// ClusterShards returns number of cluster shards that have to be running.
+// Shards removed from spec are kept until their slots are moved away.
+func (redis *Redis) ClusterShards() int32 {
+	shards := redis.Spec.Cluster.Shards
+	if redis.Status.Cluster != nil && redis.Status.Cluster.Resharding != nil &&
+		redis.Status.Cluster.Resharding.FromShards > shards {
+		return redis.Status.Cluster.Resharding.FromShards
+	}
+	return shards
+}
+
+// IsRestoring checks if master pods are restored from RDB file and have not loaded it yet
+func (redis *Redis) IsRestoring() bool {
+	return redis.Spec.Restore != nil && (redis.Status.Restore == nil || redis.Status.Restore.Phase != RestorePhaseCompleted)
+}
+
+// IsCloning checks if master pods replicate from the source Redis of cloneFrom
+func (redis *Redis) IsCloning() bool {
+	return redis.Spec.CloneFrom != nil && (redis.Status.Clone == nil || redis.Status.Clone.Phase != ClonePhaseCompleted)
+}
+
+// CloneSource returns namespaced name of the source Redis of cloneFrom
+func (redis *Redis) CloneSource() types.NamespacedName {
+	source := types.NamespacedName{Name: redis.Spec.CloneFrom.Name, Namespace: redis.Spec.CloneFrom.Namespace}
+	if source.Namespace == "" {
+		source.Namespace = redis.Namespace
+	}
+	return source
+}
+
+// AllowsCloneInto checks if Redis in namespace may clone the Redis. Cloning
+// copies the password and the dataset, so the source has to allow other
+// namespaces explicitly.
+func (redis *Redis) AllowsCloneInto(namespace string) bool {
+	if namespace == redis.Namespace {
+		return true
+	}
+	for _, allowed := range strings.Split(redis.Annotations[CloneNamespacesAnnotation], ",") {
+		if strings.TrimSpace(allowed) == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// IsReplicatingExternalMaster checks if master pods replicate from the external master
+func (redis *Redis) IsReplicatingExternalMaster() bool {
+	return redis.Spec.ExternalMaster != nil &&
+		(redis.Status.ExternalMaster == nil || redis.Status.ExternalMaster.Phase != ExternalMasterPhasePromoted)
+}
+
+// ActiveMigration returns not completed migration of Redis component or nil
+func (status *RedisStatus) ActiveMigration(component string) *RedisMigrationStatus {
+	for i := range status.Migrations {
+		migration := &status.Migrations[i]
+		if migration.Component == component && migration.Phase != MigrationPhaseCompleted {
+			return migration
+		}
+	}
+	return nil
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.spec.mode`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Masters",type=integer,JSONPath=`.status.readyMasters`
+//+kubebuilder:printcolumn:name="Replicas",type=integer,JSONPath=`.status.readyReplicas`
+//+kubebuilder:printcolumn:name="Master",type=string,JSONPath=`.status.currentMaster`
+//+kubebuilder:printcolumn:name="Status",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].reason`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // Redis is the Schema for the redis API
+// +kubebuilder:validation:XValidation:rule="self.spec.master.kind == oldSelf.spec.master.kind || !has(oldSelf.status) || !has(oldSelf.status.migrations) || !oldSelf.status.migrations.exists(m, m.component == 'redis-master' && m.phase != 'Completed')",message="master.kind can not be changed while its migration is in progress"
+// +kubebuilder:validation:XValidation:rule="self.spec.replica.kind == oldSelf.spec.replica.kind || !has(oldSelf.status) || !has(oldSelf.status.migrations) || !oldSelf.status.migrations.exists(m, m.component == 'redis-replica' && m.phase != 'Completed')",message="replica.kind can not be changed while its migration is in progress"
 type Redis struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
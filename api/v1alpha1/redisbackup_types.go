@@ -0,0 +1,128 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BackupStorageSpec is object storage backups are kept in
+type BackupStorageSpec struct {
+	// S3-compatible object storage, e.g. AWS S3 or MinIO
+	S3 S3StorageSpec `json:"s3"`
+}
+
+// S3StorageSpec is a bucket of S3-compatible object storage. Objects are
+// addressed path-style, i.e. <endpoint>/<bucket>/<key>
+type S3StorageSpec struct {
+	// URL of the storage, e.g. 'https://s3.eu-central-1.amazonaws.com' or 'http://minio.minio:9000'
+	// +kubebuilder:validation:Pattern=`^https?://[^/]+/?$`
+	Endpoint string `json:"endpoint"`
+	// Region of the bucket. Defaults to 'us-east-1'
+	// +kubebuilder:default:=us-east-1
+	Region string `json:"region,omitempty"`
+	// Name of the bucket
+	// +kubebuilder:validation:MinLength=3
+	Bucket string `json:"bucket"`
+	// Prefix of object keys, e.g. 'redis/production'
+	Prefix string `json:"prefix,omitempty"`
+	// Name of the secret in the same namespace with AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY
+	// +kubebuilder:validation:MinLength=1
+	CredentialsSecret string `json:"credentialsSecret"`
+}
+
+// RedisBackupSpec defines the desired state of RedisBackup
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable"
+type RedisBackupSpec struct {
+	// Name of the Redis instance in the same namespace to back up
+	// +kubebuilder:validation:MinLength=1
+	Redis string `json:"redis"`
+	// Object storage the backup is uploaded to
+	Storage BackupStorageSpec `json:"storage"`
+}
+
+// RedisBackupPhase is a step of backup
+// +kubebuilder:validation:Enum=Pending;Running;Completed;Failed
+type RedisBackupPhase string
+
+const (
+	// Backup waits for a ready Redis pod
+	BackupPhasePending RedisBackupPhase = "Pending"
+	// RDB snapshot is being taken and uploaded
+	BackupPhaseRunning RedisBackupPhase = "Running"
+	// RDB snapshot is stored in object storage
+	BackupPhaseCompleted RedisBackupPhase = "Completed"
+	// Backup failed, it is not retried
+	BackupPhaseFailed RedisBackupPhase = "Failed"
+)
+
+// RedisBackupStatus defines the observed state of RedisBackup
+type RedisBackupStatus struct {
+	// Current backup phase
+	Phase RedisBackupPhase `json:"phase,omitempty"`
+	// Details of the current phase
+	Message string `json:"message,omitempty"`
+	// Redis pod the snapshot is taken from
+	Pod string `json:"pod,omitempty"`
+	// Version of Redis which took the snapshot
+	RedisVersion string `json:"redisVersion,omitempty"`
+	// URL of the uploaded RDB file, e.g. 's3://backups/redis/default/cache/daily.rdb'
+	Location string `json:"location,omitempty"`
+	// Size of the RDB file in bytes
+	Size int64 `json:"size,omitempty"`
+	// Checksum of the RDB file as 'sha256:<hex>'
+	Checksum string `json:"checksum,omitempty"`
+	// Time the backup was started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// Time the backup was completed or failed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Redis",type=string,JSONPath=`.spec.redis`
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Size",type=integer,JSONPath=`.status.size`
+//+kubebuilder:printcolumn:name="Location",type=string,JSONPath=`.status.location`,priority=1
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// RedisBackup is the Schema for the redisbackups API
+type RedisBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RedisBackupSpec   `json:"spec,omitempty"`
+	Status RedisBackupStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// RedisBackupList contains a list of RedisBackup
+type RedisBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RedisBackup `json:"items"`
+}
+
+// IsFinished checks if the backup completed or failed
+func (backup *RedisBackup) IsFinished() bool {
+	return backup.Status.Phase == BackupPhaseCompleted || backup.Status.Phase == BackupPhaseFailed
+}
+
+func init() {
+	SchemeBuilder.Register(&RedisBackup{}, &RedisBackupList{})
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BackupConcurrencyPolicy decides what happens when a backup is scheduled
+// while the previous one is still running
+// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+type BackupConcurrencyPolicy string
+
+const (
+	// New backup is taken next to the running one
+	BackupConcurrencyAllow BackupConcurrencyPolicy = "Allow"
+	// New backup is skipped and reported as missed
+	BackupConcurrencyForbid BackupConcurrencyPolicy = "Forbid"
+	// Running backup is cancelled and deleted, the new one is taken instead
+	BackupConcurrencyReplace BackupConcurrencyPolicy = "Replace"
+)
+
+// BackupRetentionSpec selects completed backups to keep, older ones are
+// deleted together with their RDB files in the bucket. Failed backups are
+// deleted once a later backup completes.
+type BackupRetentionSpec struct {
+	// Number of the newest completed backups to keep
+	// +kubebuilder:validation:Minimum=1
+	Count *int32 `json:"count,omitempty"`
+	// Completed backups older than this are deleted, e.g. '168h'. The newest completed backup is always kept
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+}
+
+// RedisBackupScheduleSpec defines the desired state of RedisBackupSchedule
+type RedisBackupScheduleSpec struct {
+	// Name of the Redis instance in the same namespace to back up
+	// +kubebuilder:validation:MinLength=1
+	Redis string `json:"redis"`
+	// Cron expression in UTC, e.g. '0 3 * * *' or '@daily'
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+	// What to do when a backup is scheduled while the previous one is still running. Defaults to 'Forbid'
+	// +kubebuilder:default:=Forbid
+	ConcurrencyPolicy BackupConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+	// Stops taking backups, retention still applies
+	Suspend bool `json:"suspend,omitempty"`
+	// Backups to keep. All completed backups are kept if not set
+	Retention BackupRetentionSpec `json:"retention,omitempty"`
+	// Object storage backups are uploaded to
+	Storage BackupStorageSpec `json:"storage"`
+}
+
+// ConditionSchedulesMissed is true when the last schedules were not taken,
+// because the operator was not running or the previous backup was still running
+const ConditionSchedulesMissed = "SchedulesMissed"
+
+// RedisBackupScheduleStatus defines the observed state of RedisBackupSchedule
+type RedisBackupScheduleStatus struct {
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Time the last backup was scheduled at
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// Time the last backup completed
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+	// Name of the last backup created
+	LastBackup string `json:"lastBackup,omitempty"`
+	// Names of backups which are not finished yet
+	Active []string `json:"active,omitempty"`
+	// Number of schedules no backup was taken for
+	MissedSchedules int64 `json:"missedSchedules,omitempty"`
+	// Time of the last schedule no backup was taken for
+	LastMissedTime *metav1.Time `json:"lastMissedTime,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Redis",type=string,JSONPath=`.spec.redis`
+//+kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.spec.schedule`
+//+kubebuilder:printcolumn:name="Suspend",type=boolean,JSONPath=`.spec.suspend`
+//+kubebuilder:printcolumn:name="Last Backup",type=string,JSONPath=`.status.lastBackup`
+//+kubebuilder:printcolumn:name="Missed",type=integer,JSONPath=`.status.missedSchedules`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// RedisBackupSchedule is the Schema for the redisbackupschedules API
+type RedisBackupSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RedisBackupScheduleSpec   `json:"spec,omitempty"`
+	Status RedisBackupScheduleStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// RedisBackupScheduleList contains a list of RedisBackupSchedule
+type RedisBackupScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RedisBackupSchedule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RedisBackupSchedule{}, &RedisBackupScheduleList{})
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RedisUserSpec defines the desired state of RedisUser
+type RedisUserSpec struct {
+	// Name of the Redis instance in the same namespace the user is created in
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="redis is immutable"
+	Redis string `json:"redis"`
+	// ACL username. Defaults to the name of RedisUser
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_.:-]+$`
+	// +kubebuilder:validation:XValidation:rule="self != 'default'",message="default user is managed by Redis auth"
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="username is immutable"
+	Username string `json:"username,omitempty"`
+	// Commands the user is allowed to run, e.g. 'get' or 'client|setname'
+	Commands []string `json:"commands,omitempty"`
+	// Command categories the user is allowed to run, e.g. 'read' or 'pubsub'.
+	// See ACL CAT for available categories
+	Categories []string `json:"categories,omitempty"`
+	// Key patterns the user can access, e.g. 'orders:*'
+	Keys []string `json:"keys,omitempty"`
+	// Pub/Sub channel patterns the user can access, e.g. 'events:*'
+	Channels []string `json:"channels,omitempty"`
+}
+
+// RedisUserStatus defines the observed state of RedisUser
+type RedisUserStatus struct {
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Generation of the spec applied to Redis
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Name of the secret with REDIS_USERNAME and REDIS_PASSWORD of the user
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// RedisUserFinalizer removes the user from Redis pods before RedisUser is deleted
+const RedisUserFinalizer = "cache.assignment.yazio.com/acl-user"
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Redis",type=string,JSONPath=`.spec.redis`
+//+kubebuilder:printcolumn:name="Username",type=string,JSONPath=`.spec.username`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+//+kubebuilder:printcolumn:name="Secret",type=string,JSONPath=`.status.secretName`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// RedisUser is the Schema for the redisusers API
+type RedisUser struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RedisUserSpec   `json:"spec,omitempty"`
+	Status RedisUserStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// RedisUserList contains a list of RedisUser
+type RedisUserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RedisUser `json:"items"`
+}
+
+// ACLUsername returns name of the Redis ACL user
+func (user *RedisUser) ACLUsername() string {
+	if user.Spec.Username == "" {
+		return user.Name
+	}
+	return user.Spec.Username
+}
+
+func init() {
+	SchemeBuilder.Register(&RedisUser{}, &RedisUserList{})
+}
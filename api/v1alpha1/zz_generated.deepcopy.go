@@ -24,8 +24,50 @@ import (
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupRetentionSpec) DeepCopyInto(out *BackupRetentionSpec) {
+	*out = *in
+	if in.Count != nil {
+		in, out := &in.Count, &out.Count
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupRetentionSpec.
+func (in *BackupRetentionSpec) DeepCopy() *BackupRetentionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupRetentionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupStorageSpec) DeepCopyInto(out *BackupStorageSpec) {
+	*out = *in
+	out.S3 = in.S3
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupStorageSpec.
+func (in *BackupStorageSpec) DeepCopy() *BackupStorageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupStorageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Redis) DeepCopyInto(out *Redis) {
 	*out = *in
@@ -56,6 +98,16 @@ func (in *Redis) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RedisAuthSpec) DeepCopyInto(out *RedisAuthSpec) {
 	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RotationPeriod != nil {
+		in, out := &in.RotationPeriod, &out.RotationPeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisAuthSpec.
@@ -69,68 +121,82 @@ func (in *RedisAuthSpec) DeepCopy() *RedisAuthSpec {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RedisCommonSpec) DeepCopyInto(out *RedisCommonSpec) {
+func (in *RedisAuthStatus) DeepCopyInto(out *RedisAuthStatus) {
 	*out = *in
-	in.Image.DeepCopyInto(&out.Image)
-	out.Auth = in.Auth
+	if in.LastRotationTime != nil {
+		in, out := &in.LastRotationTime, &out.LastRotationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Rotation != nil {
+		in, out := &in.Rotation, &out.Rotation
+		*out = new(RedisPasswordRotationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisCommonSpec.
-func (in *RedisCommonSpec) DeepCopy() *RedisCommonSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisAuthStatus.
+func (in *RedisAuthStatus) DeepCopy() *RedisAuthStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(RedisCommonSpec)
+	out := new(RedisAuthStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RedisImageSpec) DeepCopyInto(out *RedisImageSpec) {
+func (in *RedisBackup) DeepCopyInto(out *RedisBackup) {
 	*out = *in
-	if in.ImagePullSecrets != nil {
-		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
-		*out = make([]v1.LocalObjectReference, len(*in))
-		copy(*out, *in)
-	}
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisImageSpec.
-func (in *RedisImageSpec) DeepCopy() *RedisImageSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisBackup.
+func (in *RedisBackup) DeepCopy() *RedisBackup {
 	if in == nil {
 		return nil
 	}
-	out := new(RedisImageSpec)
+	out := new(RedisBackup)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RedisBackup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RedisList) DeepCopyInto(out *RedisList) {
+func (in *RedisBackupList) DeepCopyInto(out *RedisBackupList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Redis, len(*in))
+		*out = make([]RedisBackup, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisList.
-func (in *RedisList) DeepCopy() *RedisList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisBackupList.
+func (in *RedisBackupList) DeepCopy() *RedisBackupList {
 	if in == nil {
 		return nil
 	}
-	out := new(RedisList)
+	out := new(RedisBackupList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *RedisList) DeepCopyObject() runtime.Object {
+func (in *RedisBackupList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -138,55 +204,83 @@ func (in *RedisList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RedisMasterSpec) DeepCopyInto(out *RedisMasterSpec) {
+func (in *RedisBackupSchedule) DeepCopyInto(out *RedisBackupSchedule) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisMasterSpec.
-func (in *RedisMasterSpec) DeepCopy() *RedisMasterSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisBackupSchedule.
+func (in *RedisBackupSchedule) DeepCopy() *RedisBackupSchedule {
 	if in == nil {
 		return nil
 	}
-	out := new(RedisMasterSpec)
+	out := new(RedisBackupSchedule)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RedisBackupSchedule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RedisReplicaSpec) DeepCopyInto(out *RedisReplicaSpec) {
+func (in *RedisBackupScheduleList) DeepCopyInto(out *RedisBackupScheduleList) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RedisBackupSchedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisReplicaSpec.
-func (in *RedisReplicaSpec) DeepCopy() *RedisReplicaSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisBackupScheduleList.
+func (in *RedisBackupScheduleList) DeepCopy() *RedisBackupScheduleList {
 	if in == nil {
 		return nil
 	}
-	out := new(RedisReplicaSpec)
+	out := new(RedisBackupScheduleList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RedisBackupScheduleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RedisSpec) DeepCopyInto(out *RedisSpec) {
+func (in *RedisBackupScheduleSpec) DeepCopyInto(out *RedisBackupScheduleSpec) {
 	*out = *in
-	in.Common.DeepCopyInto(&out.Common)
-	out.Master = in.Master
-	out.Replica = in.Replica
+	in.Retention.DeepCopyInto(&out.Retention)
+	out.Storage = in.Storage
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisSpec.
-func (in *RedisSpec) DeepCopy() *RedisSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisBackupScheduleSpec.
+func (in *RedisBackupScheduleSpec) DeepCopy() *RedisBackupScheduleSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(RedisSpec)
+	out := new(RedisBackupScheduleSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RedisStatus) DeepCopyInto(out *RedisStatus) {
+func (in *RedisBackupScheduleStatus) DeepCopyInto(out *RedisBackupScheduleStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -195,14 +289,923 @@ func (in *RedisStatus) DeepCopyInto(out *RedisStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessfulTime != nil {
+		in, out := &in.LastSuccessfulTime, &out.LastSuccessfulTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Active != nil {
+		in, out := &in.Active, &out.Active
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastMissedTime != nil {
+		in, out := &in.LastMissedTime, &out.LastMissedTime
+		*out = (*in).DeepCopy()
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisStatus.
-func (in *RedisStatus) DeepCopy() *RedisStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisBackupScheduleStatus.
+func (in *RedisBackupScheduleStatus) DeepCopy() *RedisBackupScheduleStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(RedisStatus)
+	out := new(RedisBackupScheduleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisBackupSpec) DeepCopyInto(out *RedisBackupSpec) {
+	*out = *in
+	out.Storage = in.Storage
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisBackupSpec.
+func (in *RedisBackupSpec) DeepCopy() *RedisBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisBackupStatus) DeepCopyInto(out *RedisBackupStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisBackupStatus.
+func (in *RedisBackupStatus) DeepCopy() *RedisBackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisCloneSource) DeepCopyInto(out *RedisCloneSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisCloneSource.
+func (in *RedisCloneSource) DeepCopy() *RedisCloneSource {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisCloneSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisCloneStatus) DeepCopyInto(out *RedisCloneStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisCloneStatus.
+func (in *RedisCloneStatus) DeepCopy() *RedisCloneStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisCloneStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterShardStatus) DeepCopyInto(out *RedisClusterShardStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterShardStatus.
+func (in *RedisClusterShardStatus) DeepCopy() *RedisClusterShardStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterShardStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterSpec) DeepCopyInto(out *RedisClusterSpec) {
+	*out = *in
+	in.RedisSchedulingSpec.DeepCopyInto(&out.RedisSchedulingSpec)
+	in.Persistence.DeepCopyInto(&out.Persistence)
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(RedisConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterSpec.
+func (in *RedisClusterSpec) DeepCopy() *RedisClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisClusterStatus) DeepCopyInto(out *RedisClusterStatus) {
+	*out = *in
+	if in.Shards != nil {
+		in, out := &in.Shards, &out.Shards
+		*out = make([]RedisClusterShardStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Resharding != nil {
+		in, out := &in.Resharding, &out.Resharding
+		*out = new(RedisReshardingStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisClusterStatus.
+func (in *RedisClusterStatus) DeepCopy() *RedisClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisCommonSpec) DeepCopyInto(out *RedisCommonSpec) {
+	*out = *in
+	in.Image.DeepCopyInto(&out.Image)
+	in.Auth.DeepCopyInto(&out.Auth)
+	in.TLS.DeepCopyInto(&out.TLS)
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(RedisConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.PodDisruptionBudget.DeepCopyInto(&out.PodDisruptionBudget)
+	out.Probes = in.Probes
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisCommonSpec.
+func (in *RedisCommonSpec) DeepCopy() *RedisCommonSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisCommonSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in RedisConfig) DeepCopyInto(out *RedisConfig) {
+	{
+		in := &in
+		*out = make(RedisConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisConfig.
+func (in RedisConfig) DeepCopy() RedisConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisConfig)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisConfigStatus) DeepCopyInto(out *RedisConfigStatus) {
+	*out = *in
+	if in.HotApplied != nil {
+		in, out := &in.HotApplied, &out.HotApplied
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PendingRestart != nil {
+		in, out := &in.PendingRestart, &out.PendingRestart
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisConfigStatus.
+func (in *RedisConfigStatus) DeepCopy() *RedisConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisEndpoints) DeepCopyInto(out *RedisEndpoints) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisEndpoints.
+func (in *RedisEndpoints) DeepCopy() *RedisEndpoints {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisEndpoints)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisExternalMasterSpec) DeepCopyInto(out *RedisExternalMasterSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisExternalMasterSpec.
+func (in *RedisExternalMasterSpec) DeepCopy() *RedisExternalMasterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisExternalMasterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisExternalMasterStatus) DeepCopyInto(out *RedisExternalMasterStatus) {
+	*out = *in
+	if in.LagBytes != nil {
+		in, out := &in.LagBytes, &out.LagBytes
+		*out = new(int64)
+		**out = **in
+	}
+	if in.LastIOSecondsAgo != nil {
+		in, out := &in.LastIOSecondsAgo, &out.LastIOSecondsAgo
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PromotionTime != nil {
+		in, out := &in.PromotionTime, &out.PromotionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisExternalMasterStatus.
+func (in *RedisExternalMasterStatus) DeepCopy() *RedisExternalMasterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisExternalMasterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisImageSpec) DeepCopyInto(out *RedisImageSpec) {
+	*out = *in
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisImageSpec.
+func (in *RedisImageSpec) DeepCopy() *RedisImageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisImageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisList) DeepCopyInto(out *RedisList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Redis, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisList.
+func (in *RedisList) DeepCopy() *RedisList {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RedisList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisMasterSpec) DeepCopyInto(out *RedisMasterSpec) {
+	*out = *in
+	in.RedisSchedulingSpec.DeepCopyInto(&out.RedisSchedulingSpec)
+	in.Persistence.DeepCopyInto(&out.Persistence)
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(RedisConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	in.PodDisruptionBudget.DeepCopyInto(&out.PodDisruptionBudget)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisMasterSpec.
+func (in *RedisMasterSpec) DeepCopy() *RedisMasterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisMasterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisMigrationStatus) DeepCopyInto(out *RedisMigrationStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisMigrationStatus.
+func (in *RedisMigrationStatus) DeepCopy() *RedisMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisPasswordRotationStatus) DeepCopyInto(out *RedisPasswordRotationStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisPasswordRotationStatus.
+func (in *RedisPasswordRotationStatus) DeepCopy() *RedisPasswordRotationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisPasswordRotationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisPersistenceSpec) DeepCopyInto(out *RedisPersistenceSpec) {
+	*out = *in
+	out.Size = in.Size.DeepCopy()
+	if in.AccessModes != nil {
+		in, out := &in.AccessModes, &out.AccessModes
+		*out = make([]v1.PersistentVolumeAccessMode, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisPersistenceSpec.
+func (in *RedisPersistenceSpec) DeepCopy() *RedisPersistenceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisPersistenceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisPodDisruptionBudgetSpec) DeepCopyInto(out *RedisPodDisruptionBudgetSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisPodDisruptionBudgetSpec.
+func (in *RedisPodDisruptionBudgetSpec) DeepCopy() *RedisPodDisruptionBudgetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisPodDisruptionBudgetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisProbeSpec) DeepCopyInto(out *RedisProbeSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisProbeSpec.
+func (in *RedisProbeSpec) DeepCopy() *RedisProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisProbesSpec) DeepCopyInto(out *RedisProbesSpec) {
+	*out = *in
+	out.Liveness = in.Liveness
+	out.Readiness = in.Readiness
+	out.Startup = in.Startup
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisProbesSpec.
+func (in *RedisProbesSpec) DeepCopy() *RedisProbesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisProbesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisReplicaSpec) DeepCopyInto(out *RedisReplicaSpec) {
+	*out = *in
+	in.RedisSchedulingSpec.DeepCopyInto(&out.RedisSchedulingSpec)
+	in.Persistence.DeepCopyInto(&out.Persistence)
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(RedisConfig, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	in.PodDisruptionBudget.DeepCopyInto(&out.PodDisruptionBudget)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisReplicaSpec.
+func (in *RedisReplicaSpec) DeepCopy() *RedisReplicaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisReplicaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisReshardingStatus) DeepCopyInto(out *RedisReshardingStatus) {
+	*out = *in
+	if in.CurrentSlot != nil {
+		in, out := &in.CurrentSlot, &out.CurrentSlot
+		*out = new(int32)
+		**out = **in
+	}
+	in.StartTime.DeepCopyInto(&out.StartTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisReshardingStatus.
+func (in *RedisReshardingStatus) DeepCopy() *RedisReshardingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisReshardingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisRestoreSpec) DeepCopyInto(out *RedisRestoreSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisRestoreSpec.
+func (in *RedisRestoreSpec) DeepCopy() *RedisRestoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisRestoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisRestoreStatus) DeepCopyInto(out *RedisRestoreStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisRestoreStatus.
+func (in *RedisRestoreStatus) DeepCopy() *RedisRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisSchedulingSpec) DeepCopyInto(out *RedisSchedulingSpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RuntimeClassName != nil {
+		in, out := &in.RuntimeClassName, &out.RuntimeClassName
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisSchedulingSpec.
+func (in *RedisSchedulingSpec) DeepCopy() *RedisSchedulingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisSchedulingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisSentinelSpec) DeepCopyInto(out *RedisSentinelSpec) {
+	*out = *in
+	in.RedisSchedulingSpec.DeepCopyInto(&out.RedisSchedulingSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisSentinelSpec.
+func (in *RedisSentinelSpec) DeepCopy() *RedisSentinelSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisSentinelSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisSpec) DeepCopyInto(out *RedisSpec) {
+	*out = *in
+	in.Common.DeepCopyInto(&out.Common)
+	in.Master.DeepCopyInto(&out.Master)
+	in.Replica.DeepCopyInto(&out.Replica)
+	in.Sentinel.DeepCopyInto(&out.Sentinel)
+	in.Cluster.DeepCopyInto(&out.Cluster)
+	if in.Restore != nil {
+		in, out := &in.Restore, &out.Restore
+		*out = new(RedisRestoreSpec)
+		**out = **in
+	}
+	if in.CloneFrom != nil {
+		in, out := &in.CloneFrom, &out.CloneFrom
+		*out = new(RedisCloneSource)
+		**out = **in
+	}
+	if in.ExternalMaster != nil {
+		in, out := &in.ExternalMaster, &out.ExternalMaster
+		*out = new(RedisExternalMasterSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisSpec.
+func (in *RedisSpec) DeepCopy() *RedisSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisStatus) DeepCopyInto(out *RedisStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = new(RedisEndpoints)
+		**out = **in
+	}
+	if in.Migrations != nil {
+		in, out := &in.Migrations, &out.Migrations
+		*out = make([]RedisMigrationStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Cluster != nil {
+		in, out := &in.Cluster, &out.Cluster
+		*out = new(RedisClusterStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(RedisAuthStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(RedisTLSStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(RedisConfigStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Restore != nil {
+		in, out := &in.Restore, &out.Restore
+		*out = new(RedisRestoreStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Clone != nil {
+		in, out := &in.Clone, &out.Clone
+		*out = new(RedisCloneStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExternalMaster != nil {
+		in, out := &in.ExternalMaster, &out.ExternalMaster
+		*out = new(RedisExternalMasterStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisStatus.
+func (in *RedisStatus) DeepCopy() *RedisStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisTLSSpec) DeepCopyInto(out *RedisTLSSpec) {
+	*out = *in
+	if in.CertificateDuration != nil {
+		in, out := &in.CertificateDuration, &out.CertificateDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisTLSSpec.
+func (in *RedisTLSSpec) DeepCopy() *RedisTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisTLSStatus) DeepCopyInto(out *RedisTLSStatus) {
+	*out = *in
+	if in.NotAfter != nil {
+		in, out := &in.NotAfter, &out.NotAfter
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisTLSStatus.
+func (in *RedisTLSStatus) DeepCopy() *RedisTLSStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisTLSStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisUser) DeepCopyInto(out *RedisUser) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisUser.
+func (in *RedisUser) DeepCopy() *RedisUser {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisUser)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RedisUser) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisUserList) DeepCopyInto(out *RedisUserList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RedisUser, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisUserList.
+func (in *RedisUserList) DeepCopy() *RedisUserList {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisUserList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RedisUserList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisUserSpec) DeepCopyInto(out *RedisUserSpec) {
+	*out = *in
+	if in.Commands != nil {
+		in, out := &in.Commands, &out.Commands
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Categories != nil {
+		in, out := &in.Categories, &out.Categories
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Channels != nil {
+		in, out := &in.Channels, &out.Channels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisUserSpec.
+func (in *RedisUserSpec) DeepCopy() *RedisUserSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisUserSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedisUserStatus) DeepCopyInto(out *RedisUserStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedisUserStatus.
+func (in *RedisUserStatus) DeepCopy() *RedisUserStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RedisUserStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3StorageSpec) DeepCopyInto(out *S3StorageSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3StorageSpec.
+func (in *S3StorageSpec) DeepCopy() *S3StorageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(S3StorageSpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"os"
@@ -36,6 +37,7 @@ import (
 
 	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
 	"github.com/avekrivoy/redis-operator/internal/controller"
+	webhookcachev1alpha1 "github.com/avekrivoy/redis-operator/internal/webhook/v1alpha1"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -52,11 +54,17 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "restore" {
+		os.Exit(runRestore(os.Args[2:]))
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var pruneDryRun bool
+	var restoreImage string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
@@ -66,6 +74,10 @@ func main() {
 		"If set the metrics endpoint is served securely")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.BoolVar(&pruneDryRun, "prune-dry-run", false,
+		"If set, resources no longer rendered by Redis spec are only logged instead of being deleted")
+	flag.StringVar(&restoreImage, "restore-image", "",
+		"Image of the operator run by Redis pods to restore RDB files. Defaults to the image of the operator pod")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -122,13 +134,50 @@ func main() {
 		os.Exit(1)
 	}
 
+	if restoreImage == "" {
+		// The cache is not started yet, the pod is read from the API server
+		restoreImage, err = operatorImage(context.Background(), mgr.GetAPIReader())
+		if err != nil {
+			setupLog.Error(err, "unable to detect operator image, restore is not available without --restore-image")
+		}
+	}
+
 	if err = (&controller.RedisReconciler{
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		RestoreImage: restoreImage,
+		PruneDryRun:  pruneDryRun,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Redis")
+		os.Exit(1)
+	}
+	if err = (&controller.RedisUserReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Redis")
+		setupLog.Error(err, "unable to create controller", "controller", "RedisUser")
+		os.Exit(1)
+	}
+	if err = (&controller.RedisBackupReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RedisBackup")
+		os.Exit(1)
+	}
+	if err = (&controller.RedisBackupScheduleReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RedisBackupSchedule")
 		os.Exit(1)
 	}
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = webhookcachev1alpha1.SetupRedisWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Redis")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -0,0 +1,83 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/avekrivoy/redis-operator/internal/restore"
+	"github.com/avekrivoy/redis-operator/internal/storage"
+)
+
+// managerContainerName is the name of the operator container in its pod
+const managerContainerName = "manager"
+
+// runRestore downloads RDB file into Redis data directory. It is run by init
+// container of master pods restored from backup, S3 credentials are read
+// from the environment.
+func runRestore(args []string) int {
+	var options restore.Options
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	flags.StringVar(&options.Storage.Endpoint, "endpoint", "", "URL of S3-compatible storage.")
+	flags.StringVar(&options.Storage.Region, "region", "us-east-1", "Region of the bucket.")
+	flags.StringVar(&options.Location, "location", "", "Location of the RDB file, 's3://<bucket>/<key>'.")
+	flags.StringVar(&options.Checksum, "checksum", "", "Expected checksum of the RDB file, 'sha256:<hex>'.")
+	flags.StringVar(&options.DataDir, "data-dir", "", "Redis data directory the RDB file is placed in.")
+	_ = flags.Parse(args)
+	options.Storage.AccessKeyID = os.Getenv(storage.AccessKeyIDKey)
+	options.Storage.SecretAccessKey = os.Getenv(storage.SecretAccessKeyKey)
+
+	restored, err := restore.Run(ctrl.SetupSignalHandler(), options)
+	if err != nil {
+		// Written to stderr, the pod reports it as termination message of the container
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if !restored {
+		fmt.Printf("%s holds data already, skipping restore\n", options.DataDir)
+		return 0
+	}
+	fmt.Printf("Restored %s into %s\n", options.Location, options.DataDir)
+	return 0
+}
+
+// operatorImage returns image of the operator container, read from the pod
+// named by POD_NAME and POD_NAMESPACE set with the downward API
+func operatorImage(ctx context.Context, reader client.Reader) (string, error) {
+	name, namespace := os.Getenv("POD_NAME"), os.Getenv("POD_NAMESPACE")
+	if name == "" || namespace == "" {
+		return "", fmt.Errorf("POD_NAME and POD_NAMESPACE are not set")
+	}
+	pod := &corev1.Pod{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, pod); err != nil {
+		return "", err
+	}
+	for _, container := range pod.Spec.Containers {
+		if container.Name == managerContainerName {
+			return container.Image, nil
+		}
+	}
+	return "", fmt.Errorf("pod %s/%s has no %s container", namespace, name, managerContainerName)
+}
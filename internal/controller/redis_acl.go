@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	"github.com/avekrivoy/redis-operator/internal/metadata"
+	"github.com/avekrivoy/redis-operator/internal/resources"
+)
+
+// reconcileACLFile renders ACL file loaded by Redis pods on startup. It holds
+// the default user with passwords of the auth secret and users declared by
+// RedisUser resources, which are applied to running pods by RedisUser controller.
+func (r *RedisReconciler) reconcileACLFile(ctx context.Context, redis *cachev1alpha1.Redis) error {
+	passwords, err := r.defaultUserPasswords(ctx, redis)
+	if err != nil {
+		return err
+	}
+	lines := []string{resources.ACLFileLine("default", resources.DefaultUserACLRules(passwords))}
+
+	users, err := redisUsers(ctx, r.Client, redis)
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if !user.DeletionTimestamp.IsZero() {
+			continue
+		}
+		password, err := userPassword(ctx, r.Client, &user)
+		if k8serrors.IsNotFound(err) {
+			// Secret is created by RedisUser controller, which triggers another reconcile
+			continue
+		} else if err != nil {
+			return err
+		}
+		lines = append(lines, resources.ACLFileLine(user.ACLUsername(), resources.UserACLRules(&user.Spec, password)))
+	}
+
+	secret := &corev1.Secret{}
+	secret.SetName(metadata.RedisACLSecretName(redis.Name))
+	secret.SetNamespace(redis.Namespace)
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		secret.Labels = metadata.ResourceLabels(redis.Name, metadata.Label{
+			"app.kubernetes.io/component": metadata.DefaultComponent,
+		})
+		secret.Data = map[string][]byte{
+			resources.ACLFileKey: []byte(strings.Join(lines, "\n") + "\n"),
+		}
+		return controllerutil.SetControllerReference(redis, secret, r.Scheme)
+	})
+	return err
+}
+
+// defaultUserPasswords returns passwords accepted by the default user, all
+// passwords of the generated secret are accepted while rotation is in progress
+func (r *RedisReconciler) defaultUserPasswords(ctx context.Context, redis *cachev1alpha1.Redis) ([]string, error) {
+	if !redis.Spec.Common.Auth.IsEnabled() {
+		return nil, nil
+	}
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{
+		Name:      resources.AuthSecretName(redis),
+		Namespace: redis.Namespace,
+	}, secret); err != nil {
+		return nil, err
+	}
+
+	passwords := []string{}
+	for _, key := range []string{resources.AuthPasswordKey, resources.AuthPreviousPasswordKey, resources.AuthNextPasswordKey} {
+		if password := string(secret.Data[key]); password != "" {
+			passwords = append(passwords, password)
+		}
+	}
+	if len(passwords) == 0 {
+		return nil, fmt.Errorf("secret %s has no %s key", secret.Name, resources.AuthPasswordKey)
+	}
+	return passwords, nil
+}
+
+// redisUsers lists RedisUser resources of Redis instance sorted by username
+func redisUsers(ctx context.Context, c client.Reader, redis *cachev1alpha1.Redis) ([]cachev1alpha1.RedisUser, error) {
+	userList := &cachev1alpha1.RedisUserList{}
+	if err := c.List(ctx, userList, client.InNamespace(redis.Namespace)); err != nil {
+		return nil, err
+	}
+
+	users := []cachev1alpha1.RedisUser{}
+	for _, user := range userList.Items {
+		if user.Spec.Redis == redis.Name {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ACLUsername() < users[j].ACLUsername()
+	})
+	return users, nil
+}
+
+// userPassword returns password of RedisUser from its secret
+func userPassword(ctx context.Context, c client.Reader, user *cachev1alpha1.RedisUser) (string, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{
+		Name:      metadata.RedisUserSecretName(user.Name),
+		Namespace: user.Namespace,
+	}, secret); err != nil {
+		return "", err
+	}
+	return string(secret.Data[resources.AuthPasswordKey]), nil
+}
+
+// redisForUser enqueues Redis instance the user belongs to
+func redisForUser(_ context.Context, object client.Object) []reconcile.Request {
+	user, ok := object.(*cachev1alpha1.RedisUser)
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{
+		Name:      user.Spec.Redis,
+		Namespace: user.Namespace,
+	}}}
+}
@@ -0,0 +1,192 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	"github.com/avekrivoy/redis-operator/internal/metadata"
+	redisclient "github.com/avekrivoy/redis-operator/internal/redis"
+	"github.com/avekrivoy/redis-operator/internal/resources"
+)
+
+const cloneRequeuePeriod = 5 * time.Second
+
+// errCloneSource is returned when the source Redis can not be cloned, e.g. it
+// does not exist yet, cloning is retried
+var errCloneSource = errors.New("source Redis can not be cloned")
+
+// reconcileClone drives master pods replicating from the source Redis of
+// cloneFrom. Master pods are rendered as masters, so the pod template does
+// not change when cloning completes: the operator points them to the source
+// with REPLICAOF at runtime, and detaches them with REPLICAOF NO ONE once all
+// of them are in sync. It returns true while cloning is in progress.
+func (r *RedisReconciler) reconcileClone(ctx context.Context, redis *cachev1alpha1.Redis) (bool, error) {
+	if !redis.IsCloning() {
+		return false, nil
+	}
+
+	previous := redis.Status.Clone.DeepCopy()
+	if redis.Status.Clone == nil {
+		redis.Status.Clone = &cachev1alpha1.RedisCloneStatus{
+			Phase:     cachev1alpha1.ClonePhaseSyncing,
+			StartTime: metav1.Now(),
+		}
+	}
+	status := redis.Status.Clone
+
+	err := r.syncClone(ctx, redis)
+	if errors.Is(err, errCloneSource) {
+		status.Phase = cachev1alpha1.ClonePhaseFailed
+		status.Message = err.Error()
+	} else if err != nil {
+		return false, err
+	}
+
+	if status.Phase == cachev1alpha1.ClonePhaseCompleted {
+		log.FromContext(ctx).Info("Detached from source Redis", "source", redis.CloneSource())
+	}
+	if !equality.Semantic.DeepEqual(previous, status) {
+		if err := r.Status().Update(ctx, redis); err != nil {
+			return false, err
+		}
+	}
+	return status.Phase != cachev1alpha1.ClonePhaseCompleted, nil
+}
+
+// syncClone points master pods to the source Redis and detaches them once all
+// of them are in sync with it, setting the clone phase
+func (r *RedisReconciler) syncClone(ctx context.Context, redis *cachev1alpha1.Redis) error {
+	source := &cachev1alpha1.Redis{}
+	if err := r.Get(ctx, redis.CloneSource(), source); k8serrors.IsNotFound(err) {
+		return fmt.Errorf("%w: %s not found", errCloneSource, redis.CloneSource())
+	} else if err != nil {
+		return err
+	}
+	if !source.AllowsCloneInto(redis.Namespace) {
+		return fmt.Errorf("%w: %s does not allow cloning into namespace %s, it has to be listed in its %s annotation",
+			errCloneSource, redis.CloneSource(), redis.Namespace, cachev1alpha1.CloneNamespacesAnnotation)
+	}
+	if source.Spec.Mode == cachev1alpha1.ModeCluster {
+		return fmt.Errorf("%w: %s runs in cluster mode", errCloneSource, redis.CloneSource())
+	}
+	// Master pods replicate with TLS settings of the clone, they have to match the source
+	if source.Spec.Common.TLS.Enabled != redis.Spec.Common.TLS.Enabled {
+		return fmt.Errorf("%w: TLS is enabled in one of %s and the clone only", errCloneSource, redis.CloneSource())
+	}
+	sourcePassword, err := redisPassword(ctx, r.Client, source)
+	if err != nil {
+		return err
+	}
+
+	pods, err := listPods(ctx, r.Client, redis, metadata.LabelSelector(redis.Name, metadata.RedisMasterComponent()))
+	if err != nil {
+		return err
+	}
+	options, err := connectionOptions(ctx, r.Client, redis)
+	if err != nil {
+		return err
+	}
+
+	status := redis.Status.Clone
+	status.Phase = cachev1alpha1.ClonePhaseSyncing
+	sourceHost := resources.CloneSourceHost(redis)
+	inSync := 0
+	messages := []string{}
+	for _, pod := range pods {
+		if pod.Status.PodIP == "" {
+			messages = append(messages, fmt.Sprintf("%s is starting", pod.Name))
+			continue
+		}
+		message, err := replicateFrom(ctx, &pod, options, sourceHost, redisPort, sourcePassword)
+		if err != nil {
+			messages = append(messages, fmt.Sprintf("%s is unreachable: %v", pod.Name, err))
+			continue
+		}
+		if message == "" {
+			inSync++
+			continue
+		}
+		messages = append(messages, fmt.Sprintf("%s %s", pod.Name, message))
+	}
+
+	if len(pods) > 0 && inSync == len(pods) && int32(inSync) >= redis.Spec.Master.Count {
+		for _, pod := range pods {
+			if err := detach(ctx, &pod, options); err != nil {
+				return fmt.Errorf("failed to detach %s from source Redis: %w", pod.Name, err)
+			}
+		}
+		now := metav1.Now()
+		status.Phase = cachev1alpha1.ClonePhaseCompleted
+		status.Message = fmt.Sprintf("Master pods detached from %s", redis.CloneSource())
+		status.CompletionTime = &now
+		return nil
+	}
+	if len(messages) == 0 {
+		messages = append(messages, "master pods are not created yet")
+	}
+	status.Message = fmt.Sprintf("Replicating from %s: %s", redis.CloneSource(), strings.Join(messages, "; "))
+	return nil
+}
+
+// replicateFrom points Redis in the pod to the master at host and port,
+// authenticated with password. It returns empty message once the pod is in
+// sync, or the sync progress.
+func replicateFrom(ctx context.Context, pod *corev1.Pod, options redisclient.Options, host string, port int, password string) (string, error) {
+	redisClient, err := redisclient.Dial(ctx, podAddress(pod), options)
+	if err != nil {
+		return "", err
+	}
+	defer redisClient.Close()
+	replication, err := redisClient.Replication(ctx)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case replication.IsMaster() || replication.MasterHost != host:
+		if err := redisClient.ConfigSet(ctx, "masterauth", password); err != nil {
+			return "", err
+		}
+		if err := redisClient.ReplicaOf(ctx, host, port); err != nil {
+			return "", err
+		}
+		log.FromContext(ctx).Info("Started replication of pod", "pod", pod.Name, "master", net.JoinHostPort(host, strconv.Itoa(port)))
+		return "started replication", nil
+	case replication.InSync():
+		return "", nil
+	case replication.MasterSyncInProgress:
+		return "is doing full sync", nil
+	}
+	return "has link to master down", nil
+}
+
+// detach promotes Redis in the pod to master with REPLICAOF NO ONE, and
+// restores masterauth to the password of the Redis, which Bitnami image sets
+// for masters, as the pod may become a replica of another pod later
+func detach(ctx context.Context, pod *corev1.Pod, options redisclient.Options) error {
+	redisClient, err := redisclient.Dial(ctx, podAddress(pod), options)
+	if err != nil {
+		return err
+	}
+	defer redisClient.Close()
+	replication, err := redisClient.Replication(ctx)
+	if err != nil {
+		return err
+	}
+	if err := redisClient.ReplicaOfNoOne(ctx); err != nil {
+		return err
+	}
+	log.FromContext(ctx).Info("Detached pod from its master", "pod", pod.Name, "offset", replication.SlaveReplOffset)
+	return redisClient.ConfigSet(ctx, "masterauth", options.Password)
+}
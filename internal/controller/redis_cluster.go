@@ -0,0 +1,321 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	"github.com/avekrivoy/redis-operator/internal/metadata"
+	redisclient "github.com/avekrivoy/redis-operator/internal/redis"
+)
+
+const (
+	clusterRequeuePeriod          = 30 * time.Second
+	clusterBootstrapRequeuePeriod = 5 * time.Second
+)
+
+// clusterNode is a ready pod of Redis Cluster with connection to it
+type clusterNode struct {
+	pod     corev1.Pod
+	shard   int32
+	ordinal int
+	client  *redisclient.Client
+	myself  *redisclient.ClusterNode
+}
+
+// clusterTopology groups cluster nodes by shard, ordered by pod ordinal
+type clusterTopology struct {
+	shards [][]*clusterNode
+	nodes  []*clusterNode
+}
+
+func (t *clusterTopology) close() {
+	for _, node := range t.nodes {
+		node.client.Close()
+	}
+}
+
+// nodeByID returns running node with given cluster node ID
+func (t *clusterTopology) nodeByID(id string) *clusterNode {
+	for _, node := range t.nodes {
+		if node.myself.ID == id {
+			return node
+		}
+	}
+	return nil
+}
+
+// shardMaster returns node serving slots of the shard. Replicas can be promoted
+// by cluster failover, so the pod with ordinal 0 is used only if no node owns slots.
+func (t *clusterTopology) shardMaster(shard int32) *clusterNode {
+	nodes := t.shards[shard]
+	for _, node := range nodes {
+		if node.myself.IsMaster() && len(node.myself.Slots) > 0 {
+			return node
+		}
+	}
+	if len(nodes) == 0 {
+		return nil
+	}
+	return nodes[0]
+}
+
+// reconcileCluster joins cluster nodes, assigns slots nobody owns to shard
+// masters, attaches replicas, reshards the cluster after shard count change
+// and records slot ownership in status. It returns false while the cluster is
+// still being bootstrapped or resharded.
+func (r *RedisReconciler) reconcileCluster(ctx context.Context, redis *cachev1alpha1.Redis) (bool, error) {
+	logger := log.FromContext(ctx)
+	spec := redis.Spec.Cluster
+	previous := redis.Status.Cluster.DeepCopy()
+	startResharding(ctx, redis)
+
+	options, err := connectionOptions(ctx, r.Client, redis)
+	if err != nil {
+		return false, err
+	}
+
+	topology, err := r.clusterTopology(ctx, redis, options)
+	if err != nil {
+		return false, err
+	}
+	defer topology.close()
+
+	expected := int(redis.ClusterShards() * (spec.ReplicasPerShard + 1))
+	if len(topology.nodes) < expected {
+		logger.Info("Waiting for cluster nodes", "ready", len(topology.nodes), "expected", expected)
+		return false, r.updateClusterStatus(ctx, redis, previous, topology, nil)
+	}
+
+	entry := topology.nodes[0]
+	known, err := entry.client.ClusterNodes(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	met, err := r.meetClusterNodes(ctx, topology, known)
+	if err != nil || met {
+		return false, err
+	}
+	r.forgetStaleNodes(ctx, topology, known)
+
+	clusterInfo, err := entry.client.ClusterInfo(ctx)
+	if err != nil {
+		return false, err
+	}
+	assigned, err := r.assignSlots(ctx, redis, topology, known)
+	if err != nil || assigned {
+		return false, err
+	}
+
+	attached := true
+	for shard := range topology.shards {
+		// Masters of drained shards may become replicas of the shard which took their last slot
+		if int32(shard) >= spec.Shards {
+			continue
+		}
+		master := topology.shardMaster(int32(shard))
+		for _, node := range topology.shards[shard] {
+			if node == master || node.myself.MasterID == master.myself.ID {
+				continue
+			}
+			logger.Info("Attaching replica to shard master", "pod", node.pod.Name, "master", master.pod.Name)
+			if err := node.client.ClusterReplicate(ctx, master.myself.ID); err != nil {
+				// Replica may not know the master yet, gossip takes a moment
+				logger.Error(err, "Failed to attach replica", "pod", node.pod.Name)
+			}
+			attached = false
+		}
+	}
+
+	if !attached {
+		return false, r.updateClusterStatus(ctx, redis, previous, topology, clusterInfo)
+	}
+
+	resharded, err := r.reconcileResharding(ctx, redis, topology, options)
+	if statusErr := r.updateClusterStatus(ctx, redis, previous, topology, clusterInfo); err == nil {
+		err = statusErr
+	}
+	return resharded, err
+}
+
+// clusterTopology connects to every ready cluster node
+func (r *RedisReconciler) clusterTopology(ctx context.Context, redis *cachev1alpha1.Redis, options redisclient.Options) (*clusterTopology, error) {
+	topology := &clusterTopology{
+		shards: make([][]*clusterNode, redis.ClusterShards()),
+	}
+
+	for shard := int32(0); shard < redis.ClusterShards(); shard++ {
+		pods, err := listPods(ctx, r.Client, redis, metadata.ShardLabelSelector(redis.Name, shard))
+		if err != nil {
+			topology.close()
+			return nil, err
+		}
+
+		for _, pod := range readyPods(pods) {
+			node, err := connectClusterNode(ctx, pod, shard, options)
+			if err != nil {
+				log.FromContext(ctx).Error(err, "Failed to connect to cluster node", "pod", pod.Name)
+				continue
+			}
+			topology.shards[shard] = append(topology.shards[shard], node)
+			topology.nodes = append(topology.nodes, node)
+		}
+		sort.Slice(topology.shards[shard], func(i, j int) bool {
+			return topology.shards[shard][i].ordinal < topology.shards[shard][j].ordinal
+		})
+	}
+	return topology, nil
+}
+
+func connectClusterNode(ctx context.Context, pod corev1.Pod, shard int32, options redisclient.Options) (*clusterNode, error) {
+	ordinal, err := strconv.Atoi(pod.Name[strings.LastIndex(pod.Name, "-")+1:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pod ordinal: %w", err)
+	}
+
+	redisClient, err := redisclient.Dial(ctx, podAddress(&pod), options)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every node may become a replica after failover and has to authenticate to its master
+	if err := redisClient.ConfigSet(ctx, "masterauth", options.Password); err != nil {
+		redisClient.Close()
+		return nil, err
+	}
+
+	myself, err := redisClient.ClusterMyself(ctx)
+	if err != nil {
+		redisClient.Close()
+		return nil, err
+	}
+
+	return &clusterNode{
+		pod:     pod,
+		shard:   shard,
+		ordinal: ordinal,
+		client:  redisClient,
+		myself:  myself,
+	}, nil
+}
+
+// meetClusterNodes introduces nodes unknown to the entry node or known by
+// an outdated address, e.g. after pod restart. It returns true if any MEET was sent.
+func (r *RedisReconciler) meetClusterNodes(ctx context.Context, topology *clusterTopology, known []redisclient.ClusterNode) (bool, error) {
+	knownIPs := map[string]string{}
+	for _, node := range known {
+		knownIPs[node.ID] = node.IP
+	}
+
+	entry := topology.nodes[0]
+	met := false
+	for _, node := range topology.nodes[1:] {
+		if knownIPs[node.myself.ID] == node.pod.Status.PodIP {
+			continue
+		}
+		log.FromContext(ctx).Info("Adding node to cluster", "pod", node.pod.Name)
+		if err := entry.client.ClusterMeet(ctx, node.pod.Status.PodIP, redisPort); err != nil {
+			return false, err
+		}
+		met = true
+	}
+	return met, nil
+}
+
+// forgetStaleNodes removes failed nodes whose pods no longer exist, e.g. nodes
+// which lost their data volume and rejoined with a new ID. FORGET has to reach
+// every node, otherwise the stale node is gossiped back.
+func (r *RedisReconciler) forgetStaleNodes(ctx context.Context, topology *clusterTopology, known []redisclient.ClusterNode) {
+	for _, stale := range known {
+		if !stale.HasFlag("fail") || topology.nodeByID(stale.ID) != nil {
+			continue
+		}
+		log.FromContext(ctx).Info("Forgetting failed cluster node", "node", stale.ID)
+		for _, node := range topology.nodes {
+			if err := node.client.ClusterForget(ctx, stale.ID); err != nil {
+				// Node may have already forgotten it
+				log.FromContext(ctx).Error(err, "Failed to forget node", "pod", node.pod.Name, "node", stale.ID)
+			}
+		}
+	}
+}
+
+// assignSlots assigns slots not owned by any node to masters of shards they
+// belong to, so slots left unassigned by a failed bootstrap are assigned on the
+// next reconcile. Every node knows its own slots for sure, the entry node view
+// may lag behind. It returns true if any slots were assigned.
+func (r *RedisReconciler) assignSlots(ctx context.Context, redis *cachev1alpha1.Redis, topology *clusterTopology, known []redisclient.ClusterNode) (bool, error) {
+	nodes := append([]redisclient.ClusterNode{}, known...)
+	for _, node := range topology.nodes {
+		nodes = append(nodes, *node.myself)
+	}
+
+	assigned := false
+	for shard, shardSlots := range redisclient.SplitSlots(int(redis.Spec.Cluster.Shards)) {
+		unassigned := redisclient.UnassignedSlots(shardSlots, nodes)
+		if len(unassigned) == 0 {
+			continue
+		}
+		master := topology.shardMaster(int32(shard))
+		if master == nil {
+			return false, fmt.Errorf("shard %d has no ready nodes to assign slots to", shard)
+		}
+		log.FromContext(ctx).Info("Assigning slots to shard master", "pod", master.pod.Name, "slots", redisclient.FormatSlots(unassigned))
+		for _, slots := range unassigned {
+			if err := master.client.ClusterAddSlots(ctx, slots); err != nil {
+				return false, fmt.Errorf("failed to assign slots %s to %s: %w", slots, master.pod.Name, err)
+			}
+		}
+		assigned = true
+	}
+	return assigned, nil
+}
+
+// updateClusterStatus records cluster state in status if it differs from the
+// previous status. Resharding progress is updated in place and kept as is.
+func (r *RedisReconciler) updateClusterStatus(ctx context.Context, redis *cachev1alpha1.Redis, previous *cachev1alpha1.RedisClusterStatus, topology *clusterTopology, clusterInfo map[string]string) error {
+	status := &cachev1alpha1.RedisClusterStatus{
+		State: "bootstrapping",
+	}
+	if redis.Status.Cluster != nil {
+		status.Resharding = redis.Status.Cluster.Resharding
+	}
+	if clusterInfo != nil {
+		status.State = clusterInfo["cluster_state"]
+		slotsAssigned, _ := strconv.Atoi(clusterInfo["cluster_slots_assigned"])
+		status.SlotsAssigned = int32(slotsAssigned)
+	}
+
+	// Shards removed by resharding are left out once it is completed
+	for shard := 0; shard < int(redis.ClusterShards()) && shard < len(topology.shards); shard++ {
+		shardStatus := cachev1alpha1.RedisClusterShardStatus{
+			Index: int32(shard),
+		}
+		if master := topology.shardMaster(int32(shard)); master != nil {
+			shardStatus.Master = master.pod.Name
+			shardStatus.NodeID = master.myself.ID
+			shardStatus.Slots = redisclient.FormatSlots(master.myself.Slots)
+			for _, node := range topology.shards[shard] {
+				if node.myself.MasterID == master.myself.ID {
+					shardStatus.Replicas++
+				}
+			}
+		}
+		status.Shards = append(status.Shards, shardStatus)
+	}
+
+	if equality.Semantic.DeepEqual(previous, status) {
+		return nil
+	}
+	redis.Status.Cluster = status
+	return r.Status().Update(ctx, redis)
+}
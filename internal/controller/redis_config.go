@@ -0,0 +1,205 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	"github.com/avekrivoy/redis-operator/internal/metadata"
+	redisclient "github.com/avekrivoy/redis-operator/internal/redis"
+	"github.com/avekrivoy/redis-operator/internal/resources"
+)
+
+// reconcileConfig applies redis.conf directives Redis supports with CONFIG SET
+// to every ready Redis pod, directives requiring restart are rolled out by the
+// config checksum of pod templates. Directives in effect are recorded in pod
+// annotation, so only changed directives are applied and reported in status.
+// Runtime directives removed from config are reset to the value pods start
+// with, so removing them does not restart pods either.
+func (r *RedisReconciler) reconcileConfig(ctx context.Context, redis *cachev1alpha1.Redis) error {
+	builder := resources.RedisResourceBuilder{Instance: redis, Scheme: r.Scheme}
+	options, err := connectionOptions(ctx, r.Client, redis)
+	if err != nil {
+		return err
+	}
+
+	applyErrs := []error{}
+	hotApplied := map[string]bool{}
+	pendingRestart := map[string]bool{}
+	configured := false
+	for _, component := range builder.ConfigComponents() {
+		config := builder.RedisConfig(component)
+		configured = configured || len(config) > 0
+
+		pods, err := listPods(ctx, r.Client, redis, metadata.LabelSelector(redis.Name, component))
+		if err != nil {
+			return err
+		}
+		for _, pod := range readyPods(pods) {
+			applied, err := r.applyConfig(ctx, &pod, options, config)
+			if err != nil {
+				applyErrs = append(applyErrs, fmt.Errorf("failed to apply config to %s: %w", pod.Name, err))
+			}
+			for _, name := range applied {
+				hotApplied[name] = true
+			}
+			for _, name := range configPendingRestart(&pod, config) {
+				pendingRestart[name] = true
+			}
+		}
+	}
+
+	previous := redis.Status.Config.DeepCopy()
+	if !configured && len(pendingRestart) == 0 {
+		redis.Status.Config = nil
+	} else {
+		if redis.Status.Config == nil {
+			redis.Status.Config = &cachev1alpha1.RedisConfigStatus{}
+		}
+		// Directives applied by the last change are kept until the next one
+		if len(hotApplied) > 0 {
+			redis.Status.Config.HotApplied = sortedKeys(hotApplied)
+		}
+		redis.Status.Config.PendingRestart = sortedKeys(pendingRestart)
+	}
+	if !equality.Semantic.DeepEqual(previous, redis.Status.Config) {
+		if err := r.Status().Update(ctx, redis); err != nil {
+			return err
+		}
+	}
+	return errors.Join(applyErrs...)
+}
+
+// applyConfig sets runtime directives of config which differ from directives
+// in effect in the pod, resets runtime directives removed from config and
+// records directives in effect in pod annotation. Pods without the annotation
+// loaded redis.conf on start, they get all runtime directives in case the
+// mounted ConfigMap was not up to date yet. It returns directives changed in
+// pods which had them in effect already.
+func (r *RedisReconciler) applyConfig(ctx context.Context, pod *corev1.Pod, options redisclient.Options, config cachev1alpha1.RedisConfig) ([]string, error) {
+	runtime, restart := resources.SplitConfig(config)
+
+	inEffect, recorded := appliedConfig(pod)
+	if !recorded {
+		inEffect = cachev1alpha1.RedisConfig{}
+		if pod.Annotations[metadata.ConfigChecksumAnnotation] == resources.ConfigChecksum(restart) {
+			for name, value := range restart {
+				inEffect[name] = value
+			}
+		}
+	}
+
+	values := cachev1alpha1.RedisConfig{}
+	for name, value := range runtime {
+		if current, ok := inEffect[name]; !ok || current != value {
+			values[name] = value
+		}
+	}
+	// Removed directives without known default stay in effect until restart
+	for name := range inEffect {
+		if _, ok := config[name]; ok || !resources.IsRuntimeConfigDirective(name) {
+			continue
+		}
+		if value, ok := resources.RuntimeConfigDefault(name); ok {
+			values[name] = value
+		}
+	}
+	changed := make([]string, 0, len(values))
+	for name := range values {
+		changed = append(changed, name)
+	}
+	sort.Strings(changed)
+	if recorded && len(changed) == 0 {
+		return nil, nil
+	}
+
+	if len(changed) > 0 {
+		redisClient, err := redisclient.Dial(ctx, podAddress(pod), options)
+		if err != nil {
+			return nil, err
+		}
+		defer redisClient.Close()
+		for _, name := range changed {
+			if err := redisClient.ConfigSet(ctx, name, values[name]); err != nil {
+				return nil, fmt.Errorf("failed to set %s: %w", name, err)
+			}
+			if _, ok := runtime[name]; ok {
+				inEffect[name] = values[name]
+			} else {
+				delete(inEffect, name)
+			}
+		}
+		log.FromContext(ctx).Info("Applied config", "pod", pod.Name, "directives", changed)
+	}
+
+	encoded, err := json.Marshal(inEffect)
+	if err != nil {
+		return nil, err
+	}
+	patch := client.MergeFrom(pod.DeepCopy())
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[metadata.AppliedConfigAnnotation] = string(encoded)
+	if err := r.Patch(ctx, pod, patch); err != nil {
+		return nil, err
+	}
+
+	if !recorded {
+		return nil, nil
+	}
+	return changed, nil
+}
+
+// configPendingRestart returns directives of config which are not in effect in
+// the pod, and directives removed from config which the pod still uses. Both
+// take effect when the pod is restarted.
+func configPendingRestart(pod *corev1.Pod, config cachev1alpha1.RedisConfig) []string {
+	inEffect, recorded := appliedConfig(pod)
+	if !recorded {
+		return nil
+	}
+	pending := []string{}
+	for name, value := range config {
+		if current, ok := inEffect[name]; !ok || current != value {
+			pending = append(pending, name)
+		}
+	}
+	for name := range inEffect {
+		if _, ok := config[name]; !ok {
+			pending = append(pending, name)
+		}
+	}
+	return pending
+}
+
+// appliedConfig returns directives recorded in pod annotation and false if
+// there are none
+func appliedConfig(pod *corev1.Pod) (cachev1alpha1.RedisConfig, bool) {
+	encoded, ok := pod.Annotations[metadata.AppliedConfigAnnotation]
+	if !ok {
+		return nil, false
+	}
+	config := cachev1alpha1.RedisConfig{}
+	if err := json.Unmarshal([]byte(encoded), &config); err != nil {
+		return nil, false
+	}
+	return config, true
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
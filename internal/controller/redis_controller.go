@@ -18,19 +18,21 @@ package controller
 
 import (
 	"context"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
-	"github.com/avekrivoy/redis-operator/internal/metadata"
 	resources "github.com/avekrivoy/redis-operator/internal/resources"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
@@ -38,13 +40,20 @@ import (
 type RedisReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// RestoreImage is image of the operator, master pods restore RDB file with it
+	RestoreImage string
+	// PruneDryRun only logs resources no longer rendered by the spec instead of deleting them
+	PruneDryRun bool
 }
 
 //+kubebuilder:rbac:groups=cache.assignment.yazio.com,resources=redis,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=cache.assignment.yazio.com,resources=redis/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=cache.assignment.yazio.com,resources=redis/finalizers,verbs=update
-//+kubebuilder:rbac:groups=core,resources=services;secrets,verbs=create;update;delete;get;list;watch
-//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=create;update;delete;get;list;watch
+//+kubebuilder:rbac:groups=cache.assignment.yazio.com,resources=redisusers,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=services;secrets;configmaps,verbs=create;update;delete;get;list;watch
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=create;update;delete;get;list;watch
+//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=create;update;delete;get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -66,45 +75,153 @@ func (r *RedisReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return ctrl.Result{}, nil
 	}
 
+	requeueAfter, err := r.reconcileRedis(ctx, redis)
+	if statusErr := r.updateStatus(ctx, redis, err); statusErr != nil {
+		if err != nil {
+			logger.Error(statusErr, "Failed to update status")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, statusErr
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	logger.Info("Finished reconciling")
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// reconcileRedis applies resources rendered for Redis and drives Redis pods
+// to the desired topology. It returns period after which Redis has to be
+// reconciled again while some change is in progress.
+func (r *RedisReconciler) reconcileRedis(ctx context.Context, redis *cachev1alpha1.Redis) (time.Duration, error) {
+	logger := log.FromContext(ctx)
+
+	if err := r.startMigrations(ctx, redis); err != nil {
+		return 0, err
+	}
+
+	// Replicas are scaled by restore phase, so it is observed before resources are applied
+	restoring, err := r.reconcileRestore(ctx, redis)
+	if err != nil {
+		return 0, err
+	}
+	// Master pods are pointed to the source Redis at runtime until cloning is completed
+	cloning, err := r.reconcileClone(ctx, redis)
+	if err != nil {
+		return 0, err
+	}
+	// Master pods are pointed to the external master at runtime until they are promoted
+	replicatingExternal, err := r.reconcileExternalMaster(ctx, redis)
+	if err != nil {
+		return 0, err
+	}
+
 	resourceBuilder := resources.RedisResourceBuilder{
-		Instance: redis,
-		Scheme:   r.Scheme,
+		Instance:     redis,
+		Scheme:       r.Scheme,
+		RestoreImage: r.RestoreImage,
 	}
 
 	builders := resourceBuilder.ResourceBuilders()
 
 	for _, builder := range builders {
-		if builder.IsDeployed() {
-			resource, err := builder.Build()
-			if err != nil {
-				return ctrl.Result{}, err
-			}
+		resource, err := builder.Build()
+		if err != nil {
+			return 0, err
+		}
 
-			// Do not recreate Redis auth secret if already exists
-			if resource.GetName() == metadata.RedisAuthSecretName(redis.Name) {
-				secret := &corev1.Secret{}
-				err = r.Client.Get(context.TODO(), types.NamespacedName{
-					Name:      metadata.RedisAuthSecretName(redis.Name),
-					Namespace: redis.Namespace,
-				}, secret)
-				if err == nil {
-					logger.Info("Redis auth secret already exists. Skipping resource creation")
-					continue
-				}
+		if builder.Outcome() == resources.OutcomeRemove {
+			if err := r.pruneResource(ctx, redis, resource); err != nil {
+				return 0, err
 			}
+			continue
+		}
 
-			_, apiError := controllerutil.CreateOrUpdate(ctx, r.Client, resource, func() error {
-				return builder.Update(resource)
-			})
+		_, apiError := controllerutil.CreateOrUpdate(ctx, r.Client, resource, func() error {
+			return builder.Update(resource)
+		})
 
-			if apiError != nil {
-				return ctrl.Result{}, err
-			}
+		if apiError != nil {
+			return 0, apiError
 		}
 	}
 
-	logger.Info("Finished reconciling")
-	return ctrl.Result{}, nil
+	if err := r.reconcileACLFile(ctx, redis); err != nil {
+		return 0, err
+	}
+
+	var requeueAfter time.Duration
+
+	if restoring {
+		logger.Info("Restore in progress")
+		requeueAfter = shortestRequeue(requeueAfter, restoreRequeuePeriod)
+	}
+	if cloning {
+		logger.Info("Cloning in progress")
+		requeueAfter = shortestRequeue(requeueAfter, cloneRequeuePeriod)
+	}
+	if replicatingExternal {
+		requeueAfter = shortestRequeue(requeueAfter, externalMasterRequeuePeriod)
+	}
+
+	tlsRequeue, err := r.reconcileTLS(ctx, redis)
+	if err != nil {
+		return 0, err
+	}
+	if tlsRequeue > 0 {
+		requeueAfter = shortestRequeue(requeueAfter, tlsRequeue)
+	}
+
+	migrating, err := r.reconcileMigrations(ctx, redis)
+	if err != nil {
+		return 0, err
+	}
+	if migrating {
+		logger.Info("Workload migration in progress")
+		requeueAfter = shortestRequeue(requeueAfter, migrationRequeuePeriod)
+	}
+
+	rotationRequeue, err := r.reconcilePasswordRotation(ctx, redis)
+	if err != nil {
+		return 0, err
+	}
+	if rotationRequeue > 0 {
+		requeueAfter = shortestRequeue(requeueAfter, rotationRequeue)
+	}
+
+	if redis.Spec.Mode == cachev1alpha1.ModeCluster {
+		bootstrapped, err := r.reconcileCluster(ctx, redis)
+		if err != nil {
+			return 0, err
+		}
+		if bootstrapped {
+			requeueAfter = shortestRequeue(requeueAfter, clusterRequeuePeriod)
+		} else {
+			logger.Info("Cluster bootstrap in progress")
+			requeueAfter = shortestRequeue(requeueAfter, clusterBootstrapRequeuePeriod)
+		}
+	} else if redis.Spec.Sentinel.Enabled {
+		if err := r.reconcileSentinel(ctx, redis); err != nil {
+			return 0, err
+		}
+		requeueAfter = shortestRequeue(requeueAfter, sentinelRequeuePeriod)
+	}
+
+	// Invalid directive values fail only here, after topology is reconciled
+	if err := r.reconcileConfig(ctx, redis); err != nil {
+		return 0, err
+	}
+
+	return requeueAfter, nil
+}
+
+// shortestRequeue returns the shortest non-zero requeue period
+func shortestRequeue(current time.Duration, period time.Duration) time.Duration {
+	if current == 0 || period < current {
+		return period
+	}
+	return current
 }
 
 func (r *RedisReconciler) getRedisInstance(ctx context.Context, namespacedName types.NamespacedName) (*cachev1alpha1.Redis, error) {
@@ -117,7 +234,11 @@ func (r *RedisReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&cachev1alpha1.Redis{}).
 		Owns(&appsv1.Deployment{}).
+		Owns(&appsv1.StatefulSet{}).
 		Owns(&corev1.Service{}).
 		Owns(&corev1.Secret{}).
+		Owns(&corev1.ConfigMap{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Watches(&cachev1alpha1.RedisUser{}, handler.EnqueueRequestsFromMapFunc(redisForUser)).
 		Complete(r)
 }
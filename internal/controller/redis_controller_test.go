@@ -22,6 +22,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -80,5 +81,28 @@ var _ = Describe("Redis Controller", func() {
 			// TODO(user): Add more specific assertions depending on your controller's reconciliation logic.
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
+
+		It("should report status conditions and endpoints", func() {
+			controllerReconciler := &RedisReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &cachev1alpha1.Redis{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.ObservedGeneration).To(Equal(resource.Generation))
+			Expect(resource.Status.Endpoints).NotTo(BeNil())
+			Expect(resource.Status.Endpoints.Master).To(Equal("test-resource-redis-master.default.svc:6379"))
+
+			// No pods are running in the test environment
+			Expect(meta.IsStatusConditionFalse(resource.Status.Conditions, cachev1alpha1.ConditionReady)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(resource.Status.Conditions, cachev1alpha1.ConditionProgressing)).To(BeTrue())
+			Expect(meta.FindStatusCondition(resource.Status.Conditions, cachev1alpha1.ConditionReplicationHealthy).Reason).To(Equal("NoMaster"))
+		})
 	})
 })
@@ -0,0 +1,250 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	"github.com/avekrivoy/redis-operator/internal/metadata"
+	redisclient "github.com/avekrivoy/redis-operator/internal/redis"
+	"github.com/avekrivoy/redis-operator/internal/resources"
+)
+
+const externalMasterRequeuePeriod = 10 * time.Second
+
+// externalReplicaState is replication state of a master pod replicating from the external master
+type externalReplicaState struct {
+	pod         string
+	replication *redisclient.ReplicationInfo
+	// syncProgress is percentage of full sync in progress, empty without one
+	syncProgress     string
+	lastIOSecondsAgo int64
+}
+
+// reconcileExternalMaster points master pods to the external master with
+// REPLICAOF, records their progress, and promotes them to normal masters with
+// REPLICAOF NO ONE once promotion is requested by the annotation and all of
+// them are in sync. Master pods are rendered as masters, so promotion does not
+// restart them. It returns true while master pods replicate from the external
+// master.
+func (r *RedisReconciler) reconcileExternalMaster(ctx context.Context, redis *cachev1alpha1.Redis) (bool, error) {
+	if !redis.IsReplicatingExternalMaster() {
+		return false, nil
+	}
+
+	previous := redis.Status.ExternalMaster.DeepCopy()
+	if redis.Status.ExternalMaster == nil {
+		redis.Status.ExternalMaster = &cachev1alpha1.RedisExternalMasterStatus{Phase: cachev1alpha1.ExternalMasterPhaseSyncing}
+	}
+	status := redis.Status.ExternalMaster
+
+	pods, err := listPods(ctx, r.Client, redis, metadata.LabelSelector(redis.Name, metadata.RedisMasterComponent()))
+	if err != nil {
+		return false, err
+	}
+	options, err := connectionOptions(ctx, r.Client, redis)
+	if err != nil {
+		return false, err
+	}
+	externalPassword, err := r.externalMasterPassword(ctx, redis)
+	if err != nil {
+		return false, err
+	}
+	external := redis.Spec.ExternalMaster
+
+	states := []externalReplicaState{}
+	messages := []string{}
+	for _, pod := range pods {
+		if pod.Status.PodIP == "" {
+			messages = append(messages, fmt.Sprintf("%s is starting", pod.Name))
+			continue
+		}
+		state, err := externalReplica(ctx, &pod, options)
+		if err != nil {
+			messages = append(messages, fmt.Sprintf("%s is unreachable: %v", pod.Name, err))
+			continue
+		}
+		if state.replication.IsMaster() || state.replication.MasterHost != external.Host {
+			message, err := replicateFrom(ctx, &pod, options, external.Host, int(external.Port), externalPassword)
+			if err != nil {
+				messages = append(messages, fmt.Sprintf("%s is unreachable: %v", pod.Name, err))
+				continue
+			}
+			messages = append(messages, fmt.Sprintf("%s %s", pod.Name, message))
+			continue
+		}
+		states = append(states, state)
+	}
+
+	inSync := len(messages) == 0 && len(states) > 0 && int32(len(states)) >= redis.Spec.Master.Count
+	status.SyncProgress = ""
+	status.LastIOSecondsAgo = nil
+	for _, state := range states {
+		switch {
+		case state.replication.InSync():
+			lastIO := state.lastIOSecondsAgo
+			if status.LastIOSecondsAgo == nil || lastIO > *status.LastIOSecondsAgo {
+				status.LastIOSecondsAgo = &lastIO
+			}
+		case state.replication.MasterSyncInProgress:
+			inSync = false
+			status.SyncProgress = state.syncProgress
+			messages = append(messages, fmt.Sprintf("%s is doing full sync, %s transferred", state.pod, state.syncProgress))
+		default:
+			inSync = false
+			messages = append(messages, fmt.Sprintf("%s has link to external master down", state.pod))
+		}
+	}
+	status.LagBytes = r.externalMasterLag(ctx, redis, states)
+
+	if inSync {
+		status.Phase = cachev1alpha1.ExternalMasterPhaseInSync
+		status.Message = "Master pods are in sync with the external master"
+	} else {
+		status.Phase = cachev1alpha1.ExternalMasterPhaseSyncing
+		if len(messages) == 0 {
+			messages = append(messages, "master pods are not created yet")
+		}
+		status.Message = strings.Join(messages, "; ")
+	}
+
+	if redis.Annotations[cachev1alpha1.PromoteAnnotation] == "true" {
+		if inSync {
+			if err := r.promote(ctx, redis, pods, options); err != nil {
+				return false, err
+			}
+		} else {
+			status.Message = "Promotion waits for master pods to be in sync: " + status.Message
+		}
+	}
+
+	if !equality.Semantic.DeepEqual(previous, status) {
+		if err := r.Status().Update(ctx, redis); err != nil {
+			return false, err
+		}
+	}
+	return status.Phase != cachev1alpha1.ExternalMasterPhasePromoted, nil
+}
+
+// externalReplica returns replication state of Redis in the pod
+func externalReplica(ctx context.Context, pod *corev1.Pod, options redisclient.Options) (externalReplicaState, error) {
+	redisClient, err := redisclient.Dial(ctx, podAddress(pod), options)
+	if err != nil {
+		return externalReplicaState{}, err
+	}
+	defer redisClient.Close()
+	fields, err := redisClient.Info(ctx, "replication")
+	if err != nil {
+		return externalReplicaState{}, err
+	}
+	state := externalReplicaState{pod: pod.Name, replication: redisclient.ParseReplicationInfo(fields)}
+	state.lastIOSecondsAgo, _ = strconv.ParseInt(fields["master_last_io_seconds_ago"], 10, 64)
+	if state.replication.MasterSyncInProgress {
+		state.syncProgress = syncProgress(fields)
+	}
+	return state, nil
+}
+
+// syncProgress returns percentage of the dataset a replica has received during full sync
+func syncProgress(fields map[string]string) string {
+	total, _ := strconv.ParseInt(fields["master_sync_total_bytes"], 10, 64)
+	read, _ := strconv.ParseInt(fields["master_sync_read_bytes"], 10, 64)
+	// Total size is unknown while master streams RDB without writing it to disk
+	if total <= 0 {
+		return fmt.Sprintf("%d bytes", read)
+	}
+	return fmt.Sprintf("%d%%", read*100/total)
+}
+
+// externalMasterLag returns the largest replication lag of master pods in sync
+// in bytes, nil if the external master can not be reached
+func (r *RedisReconciler) externalMasterLag(ctx context.Context, redis *cachev1alpha1.Redis, states []externalReplicaState) *int64 {
+	offset, err := r.externalMasterOffset(ctx, redis)
+	if err != nil {
+		log.FromContext(ctx).V(1).Info("Failed to read offset of external master", "error", err.Error())
+		return nil
+	}
+	var lag *int64
+	for _, state := range states {
+		if !state.replication.InSync() {
+			continue
+		}
+		podLag := max(offset-state.replication.SlaveReplOffset, 0)
+		if lag == nil || podLag > *lag {
+			lag = &podLag
+		}
+	}
+	return lag
+}
+
+// externalMasterPassword returns password of the external master, empty
+// without credentials secret
+func (r *RedisReconciler) externalMasterPassword(ctx context.Context, redis *cachev1alpha1.Redis) (string, error) {
+	external := redis.Spec.ExternalMaster
+	if external.CredentialsSecret == "" {
+		return "", nil
+	}
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: external.CredentialsSecret, Namespace: redis.Namespace}, secret); err != nil {
+		return "", err
+	}
+	return string(secret.Data[resources.AuthPasswordKey]), nil
+}
+
+// externalMasterOffset returns replication offset of the external master
+func (r *RedisReconciler) externalMasterOffset(ctx context.Context, redis *cachev1alpha1.Redis) (int64, error) {
+	external := redis.Spec.ExternalMaster
+	password, err := r.externalMasterPassword(ctx, redis)
+	if err != nil {
+		return 0, err
+	}
+	options := redisclient.Options{Password: password}
+	if external.TLS {
+		tlsConfig, err := redisTLSConfig(ctx, r.Client, redis)
+		if err != nil {
+			return 0, err
+		}
+		options.TLS = tlsConfig
+	}
+
+	redisClient, err := redisclient.Dial(ctx, net.JoinHostPort(external.Host, strconv.Itoa(int(external.Port))), options)
+	if err != nil {
+		return 0, err
+	}
+	defer redisClient.Close()
+	replication, err := redisClient.Replication(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return replication.MasterReplOffset, nil
+}
+
+// promote detaches master pods in sync from the external master. Writes to
+// the external master after promotion are not replicated, they should be
+// stopped before promotion is requested.
+func (r *RedisReconciler) promote(ctx context.Context, redis *cachev1alpha1.Redis, pods []corev1.Pod, options redisclient.Options) error {
+	status := redis.Status.ExternalMaster
+	for _, pod := range pods {
+		if err := detach(ctx, &pod, options); err != nil {
+			return fmt.Errorf("failed to promote %s: %w", pod.Name, err)
+		}
+	}
+
+	now := metav1.Now()
+	status.Phase = cachev1alpha1.ExternalMasterPhasePromoted
+	status.Message = fmt.Sprintf("Promoted from replica of %s", net.JoinHostPort(redis.Spec.ExternalMaster.Host, strconv.Itoa(int(redis.Spec.ExternalMaster.Port))))
+	status.PromotionTime = &now
+	status.SyncProgress = ""
+	log.FromContext(ctx).Info("Promoted master pods replicating from external master")
+	return nil
+}
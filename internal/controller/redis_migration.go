@@ -0,0 +1,387 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	"github.com/avekrivoy/redis-operator/internal/metadata"
+	redisclient "github.com/avekrivoy/redis-operator/internal/redis"
+	resources "github.com/avekrivoy/redis-operator/internal/resources"
+)
+
+const (
+	redisPort              = 6379
+	migrationRequeuePeriod = 5 * time.Second
+)
+
+// redisComponent describes workload settings of a Redis component
+type redisComponent struct {
+	name  string
+	kind  string
+	count int32
+}
+
+func redisComponents(redis *cachev1alpha1.Redis) []redisComponent {
+	return []redisComponent{
+		{
+			name:  metadata.RedisMasterComponent(),
+			kind:  redis.Spec.Master.Kind,
+			count: redis.Spec.Master.Count,
+		},
+		{
+			name:  metadata.RedisReplicaComponent(),
+			kind:  redis.Spec.Replica.Kind,
+			count: redis.Spec.Replica.Count,
+		},
+	}
+}
+
+func newWorkload(kind string) client.Object {
+	if kind == cachev1alpha1.KindStatefulSet {
+		return &appsv1.StatefulSet{}
+	}
+	return &appsv1.Deployment{}
+}
+
+func oppositeKind(kind string) string {
+	if kind == cachev1alpha1.KindStatefulSet {
+		return cachev1alpha1.KindDeployment
+	}
+	return cachev1alpha1.KindStatefulSet
+}
+
+// startMigrations records a migration for every component whose workload kind
+// differs from the kind of the workload currently running in the cluster.
+func (r *RedisReconciler) startMigrations(ctx context.Context, redis *cachev1alpha1.Redis) error {
+	logger := log.FromContext(ctx)
+	started := false
+
+	if redis.Spec.Mode == cachev1alpha1.ModeCluster {
+		return nil
+	}
+
+	for _, component := range redisComponents(redis) {
+		if component.count < 1 || redis.Status.ActiveMigration(component.name) != nil {
+			continue
+		}
+
+		sourceKind := oppositeKind(component.kind)
+		source := newWorkload(sourceKind)
+		err := r.Get(ctx, types.NamespacedName{
+			Name:      fmt.Sprintf("%s-%s", redis.Name, component.name),
+			Namespace: redis.Namespace,
+		}, source)
+		if k8serrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		// Target workload of an aborted migration is being pruned and must not become a source
+		if !metav1.IsControlledBy(source, redis) || source.GetDeletionTimestamp() != nil {
+			continue
+		}
+
+		logger.Info("Starting workload migration", "component", component.name, "from", sourceKind, "to", component.kind)
+		setMigration(redis, cachev1alpha1.RedisMigrationStatus{
+			Component: component.name,
+			From:      sourceKind,
+			To:        component.kind,
+			Phase:     cachev1alpha1.MigrationPhaseCreatingTarget,
+			Message:   "Waiting for target pods to become ready",
+			StartTime: metav1.Now(),
+		})
+		started = true
+	}
+
+	if !started {
+		return nil
+	}
+	return r.Status().Update(ctx, redis)
+}
+
+// setMigration replaces previous migration of the same component
+func setMigration(redis *cachev1alpha1.Redis, migration cachev1alpha1.RedisMigrationStatus) {
+	for i := range redis.Status.Migrations {
+		if redis.Status.Migrations[i].Component == migration.Component {
+			redis.Status.Migrations[i] = migration
+			return
+		}
+	}
+	redis.Status.Migrations = append(redis.Status.Migrations, migration)
+}
+
+// reconcileMigrations advances every active migration by at most one phase.
+// It returns true if any migration is still in progress.
+func (r *RedisReconciler) reconcileMigrations(ctx context.Context, redis *cachev1alpha1.Redis) (bool, error) {
+	inProgress := false
+	changed := false
+
+	for i := range redis.Status.Migrations {
+		migration := &redis.Status.Migrations[i]
+		if migration.Phase == cachev1alpha1.MigrationPhaseCompleted {
+			continue
+		}
+
+		phase, message := migration.Phase, migration.Message
+		if err := r.advanceMigration(ctx, redis, migration); err != nil {
+			migration.Message = err.Error()
+		}
+		if migration.Phase != cachev1alpha1.MigrationPhaseCompleted {
+			inProgress = true
+		}
+		if migration.Phase != phase || migration.Message != message {
+			changed = true
+		}
+	}
+
+	if changed {
+		if err := r.Status().Update(ctx, redis); err != nil {
+			return inProgress, err
+		}
+	}
+	return inProgress, nil
+}
+
+func (r *RedisReconciler) advanceMigration(ctx context.Context, redis *cachev1alpha1.Redis, migration *cachev1alpha1.RedisMigrationStatus) error {
+	logger := log.FromContext(ctx).WithValues("component", migration.Component, "from", migration.From, "to", migration.To)
+	isMaster := migration.Component == metadata.RedisMasterComponent()
+
+	// Kind was changed back while the migration was in progress. Until target
+	// pods serve traffic the migration is aborted: target workload is pruned by
+	// resource builders and the source one is kept as it is requested by spec.
+	// Afterwards the source is stale, so the migration is finished and a new
+	// one migrates back to the kind of spec.
+	if kind := componentKind(redis, migration.Component); kind != migration.To {
+		if migration.Phase == cachev1alpha1.MigrationPhaseCreatingTarget || migration.Phase == cachev1alpha1.MigrationPhaseSyncing {
+			logger.Info("Aborting workload migration, kind was changed", "kind", kind)
+			now := metav1.Now()
+			migration.Phase = cachev1alpha1.MigrationPhaseCompleted
+			migration.Message = fmt.Sprintf("Aborted, kind was changed to %s", kind)
+			migration.CompletionTime = &now
+			return nil
+		}
+		logger.Info("Finishing workload migration before migrating to changed kind", "kind", kind)
+	}
+
+	switch migration.Phase {
+	case cachev1alpha1.MigrationPhaseCreatingTarget:
+		targetPods, err := r.readyWorkloadPods(ctx, redis, migration.Component, migration.To)
+		if err != nil {
+			return err
+		}
+		if int32(len(targetPods)) < componentCount(redis, migration.Component) {
+			migration.Message = fmt.Sprintf("%d target pods are ready", len(targetPods))
+			return nil
+		}
+		migration.Phase = cachev1alpha1.MigrationPhaseSyncing
+		migration.Message = "Waiting for target pods to replicate data"
+
+	case cachev1alpha1.MigrationPhaseSyncing:
+		targetPods, err := r.readyWorkloadPods(ctx, redis, migration.Component, migration.To)
+		if err != nil {
+			return err
+		}
+		options, err := connectionOptions(ctx, r.Client, redis)
+		if err != nil {
+			return err
+		}
+
+		// In Sentinel mode target pods are attached to the current master
+		// together with other stray masters by Sentinel reconciliation.
+		var sourceMaster string
+		if isMaster && !redis.Spec.Sentinel.Enabled {
+			sourcePods, err := r.readyWorkloadPods(ctx, redis, migration.Component, migration.From)
+			if err != nil {
+				return err
+			}
+			if len(sourcePods) == 0 {
+				return fmt.Errorf("no ready source master pods")
+			}
+			sourceMaster = sourcePods[0].Status.PodIP
+		}
+
+		synced := 0
+		for _, pod := range targetPods {
+			ok, err := r.syncTargetPod(ctx, &pod, options, sourceMaster)
+			if err != nil {
+				return fmt.Errorf("pod %s: %w", pod.Name, err)
+			}
+			if ok {
+				synced++
+			}
+		}
+		if int32(synced) < componentCount(redis, migration.Component) {
+			migration.Message = fmt.Sprintf("%d target pods are in sync", synced)
+			return nil
+		}
+		logger.Info("Target pods are in sync, switching service")
+		migration.Phase = cachev1alpha1.MigrationPhaseSwitchingService
+		migration.Message = "Switching service to target pods"
+
+	case cachev1alpha1.MigrationPhaseSwitchingService:
+		// Service selector was switched to target pods by resource builders.
+		// Master targets are promoted only afterwards: clients get errors from
+		// a read-only replica instead of writing to the master being removed.
+		// In Sentinel mode master is elected by Sentinel after the source is removed.
+		if isMaster && !redis.Spec.Sentinel.Enabled {
+			targetPods, err := r.readyWorkloadPods(ctx, redis, migration.Component, migration.To)
+			if err != nil {
+				return err
+			}
+			options, err := connectionOptions(ctx, r.Client, redis)
+			if err != nil {
+				return err
+			}
+			for _, pod := range targetPods {
+				if err := r.promotePod(ctx, &pod, options); err != nil {
+					return fmt.Errorf("pod %s: %w", pod.Name, err)
+				}
+			}
+		}
+		migration.Phase = cachev1alpha1.MigrationPhaseRemovingSource
+		migration.Message = "Removing source workload"
+
+	case cachev1alpha1.MigrationPhaseRemovingSource:
+		source := newWorkload(migration.From)
+		source.SetName(fmt.Sprintf("%s-%s", redis.Name, migration.Component))
+		source.SetNamespace(redis.Namespace)
+		if err := r.Delete(ctx, source); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		logger.Info("Workload migration completed")
+		now := metav1.Now()
+		migration.Phase = cachev1alpha1.MigrationPhaseCompleted
+		migration.Message = ""
+		migration.CompletionTime = &now
+	}
+	return nil
+}
+
+// syncTargetPod points target pod to source master if needed and checks replication link.
+// Replica targets replicate from master service on their own, sourceMaster is empty for them.
+func (r *RedisReconciler) syncTargetPod(ctx context.Context, pod *corev1.Pod, options redisclient.Options, sourceMaster string) (bool, error) {
+	redisClient, err := redisclient.Dial(ctx, podAddress(pod), options)
+	if err != nil {
+		return false, err
+	}
+	defer redisClient.Close()
+
+	replication, err := redisClient.Replication(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if sourceMaster != "" && (replication.IsMaster() || replication.MasterHost != sourceMaster) {
+		if err := redisClient.ConfigSet(ctx, "masterauth", options.Password); err != nil {
+			return false, err
+		}
+		if err := redisClient.ReplicaOf(ctx, sourceMaster, redisPort); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	return replication.InSync(), nil
+}
+
+func (r *RedisReconciler) promotePod(ctx context.Context, pod *corev1.Pod, options redisclient.Options) error {
+	redisClient, err := redisclient.Dial(ctx, podAddress(pod), options)
+	if err != nil {
+		return err
+	}
+	defer redisClient.Close()
+
+	return redisClient.ReplicaOfNoOne(ctx)
+}
+
+// readyWorkloadPods lists ready pods of component run by workload of given kind
+func (r *RedisReconciler) readyWorkloadPods(ctx context.Context, redis *cachev1alpha1.Redis, component string, kind string) ([]corev1.Pod, error) {
+	pods, err := listPods(ctx, r.Client, redis, metadata.WorkloadLabelSelector(redis.Name, component, kind))
+	if err != nil {
+		return nil, err
+	}
+	return readyPods(pods), nil
+}
+
+// listPods lists not terminating pods of Redis instance matching labels
+func listPods(ctx context.Context, c client.Reader, redis *cachev1alpha1.Redis, labels metadata.Label) ([]corev1.Pod, error) {
+	podList := &corev1.PodList{}
+	if err := c.List(ctx, podList,
+		client.InNamespace(redis.Namespace),
+		client.MatchingLabels(labels),
+	); err != nil {
+		return nil, err
+	}
+
+	pods := []corev1.Pod{}
+	for _, pod := range podList.Items {
+		if pod.DeletionTimestamp == nil {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+func readyPods(pods []corev1.Pod) []corev1.Pod {
+	ready := []corev1.Pod{}
+	for _, pod := range pods {
+		if isPodReady(&pod) && pod.Status.PodIP != "" {
+			ready = append(ready, pod)
+		}
+	}
+	return ready
+}
+
+// redisPassword returns password of Redis pods, empty if authentication is disabled
+func redisPassword(ctx context.Context, c client.Reader, redis *cachev1alpha1.Redis) (string, error) {
+	if !redis.Spec.Common.Auth.IsEnabled() {
+		return "", nil
+	}
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{
+		Name:      resources.AuthSecretName(redis),
+		Namespace: redis.Namespace,
+	}, secret); err != nil {
+		return "", err
+	}
+	return string(secret.Data[resources.AuthPasswordKey]), nil
+}
+
+func componentCount(redis *cachev1alpha1.Redis, component string) int32 {
+	if component == metadata.RedisMasterComponent() {
+		return redis.Spec.Master.Count
+	}
+	return redis.Spec.Replica.Count
+}
+
+func componentKind(redis *cachev1alpha1.Redis, component string) string {
+	if component == metadata.RedisMasterComponent() {
+		return redis.Spec.Master.Kind
+	}
+	return redis.Spec.Replica.Kind
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func podAddress(pod *corev1.Pod) string {
+	return net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(redisPort))
+}
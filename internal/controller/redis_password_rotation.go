@@ -0,0 +1,231 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	"github.com/avekrivoy/redis-operator/internal/metadata"
+	redisclient "github.com/avekrivoy/redis-operator/internal/redis"
+	"github.com/avekrivoy/redis-operator/internal/resources"
+)
+
+const rotationRequeuePeriod = 5 * time.Second
+
+// reconcilePasswordRotation starts password rotation when it is requested by
+// annotation or rotation period elapsed and drives it through its phases:
+//   - AddingPassword: every Redis pod accepts the new password next to the old
+//     one and uses it for master connection, then the secret is switched to it
+//   - RestartingReplicas: replica workloads roll out with the new master password
+//   - RevokingPassword: the old password is removed from every Redis pod
+//
+// Clients keep working during rotation as long as they reread the secret
+// before the old password is revoked. It returns period after which rotation
+// has to be reconciled again.
+func (r *RedisReconciler) reconcilePasswordRotation(ctx context.Context, redis *cachev1alpha1.Redis) (time.Duration, error) {
+	auth := redis.Spec.Common.Auth
+	if !auth.IsEnabled() || auth.ExistingSecret != "" {
+		// Only the generated password is rotated
+		if redis.Status.Auth != nil {
+			redis.Status.Auth.Rotation = nil
+		}
+		return 0, nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{
+		Name:      metadata.RedisAuthSecretName(redis.Name),
+		Namespace: redis.Namespace,
+	}, secret); err != nil {
+		return 0, err
+	}
+
+	previous := redis.Status.Auth.DeepCopy()
+	if redis.Status.Auth == nil {
+		redis.Status.Auth = &cachev1alpha1.RedisAuthStatus{}
+	}
+	status := redis.Status.Auth
+
+	if status.Rotation == nil {
+		reason, requeueAfter := passwordRotationDue(redis, secret, time.Now())
+		if reason == "" {
+			return requeueAfter, nil
+		}
+		log.FromContext(ctx).Info("Starting password rotation", "reason", reason)
+		status.LastRotationRequest = redis.Annotations[cachev1alpha1.RotatePasswordAnnotation]
+		status.Rotation = &cachev1alpha1.RedisPasswordRotationStatus{
+			Phase:     cachev1alpha1.PasswordRotationPhaseAddingPassword,
+			Message:   reason,
+			StartTime: metav1.Now(),
+		}
+	}
+
+	err := r.advancePasswordRotation(ctx, redis, secret)
+	if err != nil {
+		status.Rotation.Message = err.Error()
+	}
+	// Secret is already switched at this point, so progress is persisted right away
+	if !equality.Semantic.DeepEqual(previous, status) {
+		if statusErr := r.Status().Update(ctx, redis); err == nil {
+			err = statusErr
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	if status.Rotation != nil {
+		return rotationRequeuePeriod, nil
+	}
+	_, requeueAfter := passwordRotationDue(redis, secret, time.Now())
+	return requeueAfter, nil
+}
+
+// passwordRotationDue returns reason to rotate password, empty if rotation is
+// not due yet, and period after which rotation period elapses
+func passwordRotationDue(redis *cachev1alpha1.Redis, secret *corev1.Secret, now time.Time) (string, time.Duration) {
+	status := redis.Status.Auth
+	if request := redis.Annotations[cachev1alpha1.RotatePasswordAnnotation]; request != "" && request != status.LastRotationRequest {
+		return "Rotation requested by annotation", 0
+	}
+
+	period := redis.Spec.Common.Auth.RotationPeriod
+	if period == nil || period.Duration <= 0 {
+		return "", 0
+	}
+	lastRotation := secret.CreationTimestamp.Time
+	if status.LastRotationTime != nil {
+		lastRotation = status.LastRotationTime.Time
+	}
+	due := lastRotation.Add(period.Duration)
+	if now.Before(due) {
+		return "", due.Sub(now)
+	}
+	return fmt.Sprintf("Rotation period %s elapsed", period.Duration), 0
+}
+
+// advancePasswordRotation performs the current rotation phase. Every phase can
+// be safely repeated, secret content tells which passwords pods accept.
+func (r *RedisReconciler) advancePasswordRotation(ctx context.Context, redis *cachev1alpha1.Redis, secret *corev1.Secret) error {
+	logger := log.FromContext(ctx)
+	rotation := redis.Status.Auth.Rotation
+
+	switch rotation.Phase {
+	case cachev1alpha1.PasswordRotationPhaseAddingPassword:
+		if _, switched := secret.Data[resources.AuthPreviousPasswordKey]; switched {
+			rotation.Phase = cachev1alpha1.PasswordRotationPhaseRestartingReplicas
+			return nil
+		}
+
+		next := string(secret.Data[resources.AuthNextPasswordKey])
+		if next == "" {
+			password, err := resources.GeneratePassword()
+			if err != nil {
+				return err
+			}
+			next = password
+			// New password is stored first, so it is not lost when the phase is repeated
+			secret.Data[resources.AuthNextPasswordKey] = []byte(next)
+			if err := r.Update(ctx, secret); err != nil {
+				return err
+			}
+		}
+
+		current := string(secret.Data[resources.AuthPasswordKey])
+		done, err := r.updateRedisPasswords(ctx, redis, current, func(redisClient *redisclient.Client) error {
+			if err := redisClient.ACLSetUser(ctx, redisclient.DefaultUser, "on", ">"+next); err != nil {
+				return err
+			}
+			return redisClient.ConfigSet(ctx, "masterauth", next)
+		})
+		if err != nil || !done {
+			return err
+		}
+
+		secret.Data[resources.AuthPreviousPasswordKey] = []byte(current)
+		secret.Data[resources.AuthPasswordKey] = []byte(next)
+		delete(secret.Data, resources.AuthNextPasswordKey)
+		if err := r.Update(ctx, secret); err != nil {
+			return err
+		}
+		logger.Info("New password added, restarting replicas")
+		rotation.Phase = cachev1alpha1.PasswordRotationPhaseRestartingReplicas
+		rotation.Message = "Secret holds the new password, replicas are being restarted"
+
+	case cachev1alpha1.PasswordRotationPhaseRestartingReplicas:
+		// Replica pod template is annotated with rotation start time by the
+		// resource builders, so workloads are rolling out at this point
+		rollingOut, err := r.rollingOutWorkloads(ctx, redis)
+		if err != nil {
+			return err
+		}
+		if len(rollingOut) > 0 {
+			rotation.Message = fmt.Sprintf("Waiting for workloads %v to roll out", rollingOut)
+			return nil
+		}
+		logger.Info("Replicas restarted, revoking old password")
+		rotation.Phase = cachev1alpha1.PasswordRotationPhaseRevokingPassword
+		rotation.Message = "Old password is being revoked"
+
+	case cachev1alpha1.PasswordRotationPhaseRevokingPassword:
+		current := string(secret.Data[resources.AuthPasswordKey])
+		done, err := r.updateRedisPasswords(ctx, redis, current, func(redisClient *redisclient.Client) error {
+			// Passwords are reset, so the phase can be repeated after the old one is removed
+			return redisClient.ACLSetUser(ctx, redisclient.DefaultUser, "resetpass", ">"+current)
+		})
+		if err != nil || !done {
+			return err
+		}
+
+		if _, ok := secret.Data[resources.AuthPreviousPasswordKey]; ok {
+			delete(secret.Data, resources.AuthPreviousPasswordKey)
+			if err := r.Update(ctx, secret); err != nil {
+				return err
+			}
+		}
+		logger.Info("Password rotation completed")
+		redis.Status.Auth.LastRotationTime = &rotation.StartTime
+		redis.Status.Auth.Rotation = nil
+	}
+	return nil
+}
+
+// updateRedisPasswords runs update on every Redis pod authenticated with
+// password. It returns false when some pod is not ready, so the update has to
+// be repeated once it is.
+func (r *RedisReconciler) updateRedisPasswords(ctx context.Context, redis *cachev1alpha1.Redis, password string, update func(*redisclient.Client) error) (bool, error) {
+	rotation := redis.Status.Auth.Rotation
+	tlsConfig, err := redisTLSConfig(ctx, r.Client, redis)
+	if err != nil {
+		return false, err
+	}
+	options := redisclient.Options{Password: password, TLS: tlsConfig}
+	for _, component := range []string{metadata.RedisMasterComponent(), metadata.RedisReplicaComponent(), metadata.RedisClusterComponent()} {
+		pods, err := listPods(ctx, r.Client, redis, metadata.LabelSelector(redis.Name, component))
+		if err != nil {
+			return false, err
+		}
+		for _, pod := range pods {
+			if !isPodReady(&pod) || pod.Status.PodIP == "" {
+				rotation.Message = fmt.Sprintf("Waiting for pod %s to become ready", pod.Name)
+				return false, nil
+			}
+			redisClient, err := redisclient.Dial(ctx, podAddress(&pod), options)
+			if err != nil {
+				return false, fmt.Errorf("failed to connect to %s: %w", pod.Name, err)
+			}
+			err = update(redisClient)
+			redisClient.Close()
+			if err != nil {
+				return false, fmt.Errorf("failed to update password of %s: %w", pod.Name, err)
+			}
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+)
+
+// pruneResource deletes resource which the current spec no longer renders,
+// e.g. replica Service after scaling replicas to zero. Resources not
+// controlled by Redis, like a user provided secret with the same name, are kept.
+func (r *RedisReconciler) pruneResource(ctx context.Context, redis *cachev1alpha1.Redis, resource client.Object) error {
+	if err := r.Get(ctx, client.ObjectKeyFromObject(resource), resource); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(resource, redis) || resource.GetDeletionTimestamp() != nil {
+		return nil
+	}
+
+	kind := ""
+	if gvk, err := apiutil.GVKForObject(resource, r.Scheme); err == nil {
+		kind = gvk.Kind
+	}
+	logger := log.FromContext(ctx).WithValues("kind", kind, "name", resource.GetName())
+
+	if r.PruneDryRun {
+		logger.Info("Dry run: resource is no longer rendered and would be pruned")
+		return nil
+	}
+	logger.Info("Pruning resource no longer rendered")
+	return client.IgnoreNotFound(r.Delete(ctx, resource, client.PropagationPolicy(metav1.DeletePropagationBackground)))
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+)
+
+var _ = Describe("Redis pruning", func() {
+	ctx := context.Background()
+
+	var (
+		scheme  *runtime.Scheme
+		redis   *cachev1alpha1.Redis
+		deletes int
+	)
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(cachev1alpha1.AddToScheme(scheme)).To(Succeed())
+		redis = &cachev1alpha1.Redis{ObjectMeta: metav1.ObjectMeta{Name: "cache", Namespace: "default", UID: "cache-uid"}}
+		deletes = 0
+	})
+
+	configMap := func(name string, controlled bool) *corev1.ConfigMap {
+		configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+		if controlled {
+			Expect(controllerutil.SetControllerReference(redis, configMap, scheme)).To(Succeed())
+		}
+		return configMap
+	}
+
+	// reconciler returns reconciler with fake client holding objects, which counts deletions
+	reconciler := func(dryRun bool, objects ...client.Object) *RedisReconciler {
+		fakeClient := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(objects...).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+					deletes++
+					return c.Delete(ctx, obj, opts...)
+				},
+			}).
+			Build()
+		return &RedisReconciler{Client: fakeClient, Scheme: scheme, PruneDryRun: dryRun}
+	}
+
+	// exists checks if ConfigMap is still stored
+	exists := func(r *RedisReconciler, name string) bool {
+		err := r.Get(ctx, client.ObjectKey{Name: name, Namespace: "default"}, &corev1.ConfigMap{})
+		if k8serrors.IsNotFound(err) {
+			return false
+		}
+		Expect(err).NotTo(HaveOccurred())
+		return true
+	}
+
+	It("should delete resource controlled by the Redis", func() {
+		r := reconciler(false, configMap("owned", true))
+		Expect(r.pruneResource(ctx, redis, configMap("owned", false))).To(Succeed())
+		Expect(exists(r, "owned")).To(BeFalse())
+		Expect(deletes).To(Equal(1))
+	})
+
+	It("should keep resource not controlled by the Redis", func() {
+		r := reconciler(false, configMap("user-provided", false))
+		Expect(r.pruneResource(ctx, redis, configMap("user-provided", false))).To(Succeed())
+		Expect(exists(r, "user-provided")).To(BeTrue())
+		Expect(deletes).To(BeZero())
+	})
+
+	It("should skip resource already being deleted", func() {
+		deleting := configMap("deleting", true)
+		deleting.Finalizers = []string{"example.com/cleanup"}
+		deleting.DeletionTimestamp = &metav1.Time{Time: metav1.Now().Time}
+		r := reconciler(false, deleting)
+		Expect(r.pruneResource(ctx, redis, configMap("deleting", false))).To(Succeed())
+		Expect(deletes).To(BeZero())
+	})
+
+	It("should ignore missing resource", func() {
+		r := reconciler(false)
+		Expect(r.pruneResource(ctx, redis, configMap("missing", false))).To(Succeed())
+		Expect(deletes).To(BeZero())
+	})
+
+	It("should only log resource in dry-run mode", func() {
+		r := reconciler(true, configMap("owned", true))
+		Expect(r.pruneResource(ctx, redis, configMap("owned", false))).To(Succeed())
+		Expect(exists(r, "owned")).To(BeTrue())
+		Expect(deletes).To(BeZero())
+	})
+})
@@ -0,0 +1,198 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	"github.com/avekrivoy/redis-operator/internal/metadata"
+	redisclient "github.com/avekrivoy/redis-operator/internal/redis"
+)
+
+const (
+	// reshardingBudget limits time spent moving slots in a single reconcile
+	reshardingBudget = 10 * time.Second
+	// migrateBatchSize is number of keys moved by a single MIGRATE command
+	migrateBatchSize = 100
+	migrateTimeout   = 5 * time.Second
+)
+
+// startResharding records resharding when shard count in spec differs from
+// the number of shards serving slots. Shard count changed during resharding
+// retargets it, shards added in the meantime are drained again if needed.
+func startResharding(ctx context.Context, redis *cachev1alpha1.Redis) {
+	status := redis.Status.Cluster
+	if status == nil || status.SlotsAssigned != redisclient.ClusterSlots {
+		return
+	}
+
+	shards := redis.Spec.Cluster.Shards
+	resharding := status.Resharding
+	if resharding == nil {
+		if int32(len(status.Shards)) == shards {
+			return
+		}
+		log.FromContext(ctx).Info("Starting cluster resharding", "from", len(status.Shards), "to", shards)
+		status.Resharding = &cachev1alpha1.RedisReshardingStatus{
+			Step:       cachev1alpha1.ReshardingStepMigratingSlots,
+			FromShards: int32(len(status.Shards)),
+			ToShards:   shards,
+			StartTime:  metav1.Now(),
+		}
+		return
+	}
+
+	if resharding.ToShards != shards {
+		log.FromContext(ctx).Info("Retargeting cluster resharding", "from", resharding.ToShards, "to", shards)
+		if resharding.ToShards > resharding.FromShards {
+			resharding.FromShards = resharding.ToShards
+		}
+		resharding.ToShards = shards
+		resharding.Step = cachev1alpha1.ReshardingStepMigratingSlots
+		resharding.SlotsToMove = 0
+		resharding.SlotsMoved = 0
+	}
+}
+
+// reconcileResharding moves slots between shard masters until they are split
+// evenly between shards in spec and removes drained shards afterwards.
+// Progress is recorded in status, the caller persists it. It returns true when
+// no resharding is in progress.
+func (r *RedisReconciler) reconcileResharding(ctx context.Context, redis *cachev1alpha1.Redis, topology *clusterTopology, options redisclient.Options) (bool, error) {
+	resharding := redis.Status.Cluster.Resharding
+	if resharding == nil {
+		return true, nil
+	}
+	logger := log.FromContext(ctx).WithValues("from", resharding.FromShards, "to", resharding.ToShards)
+
+	switch resharding.Step {
+	case cachev1alpha1.ReshardingStepMigratingSlots:
+		owned := make([][]redisclient.SlotRange, len(topology.shards))
+		masters := make([]*clusterNode, len(topology.shards))
+		for shard := range topology.shards {
+			masters[shard] = topology.shardMaster(int32(shard))
+			if masters[shard] == nil {
+				return false, fmt.Errorf("shard %d has no running master", shard)
+			}
+			owned[shard] = append(owned[shard], masters[shard].myself.Slots...)
+		}
+
+		// Finish slot left in migrating state by previous reconcile
+		if resharding.CurrentSlot != nil {
+			source := topology.nodeByID(resharding.SourceNodeID)
+			target := topology.nodeByID(resharding.TargetNodeID)
+			if source == nil || target == nil {
+				return false, fmt.Errorf("nodes migrating slot %d are not running", *resharding.CurrentSlot)
+			}
+			if err := r.migrateSlot(ctx, redis, topology, int(*resharding.CurrentSlot), source, target, options); err != nil {
+				return false, err
+			}
+			redisclient.SlotMove{Slot: int(*resharding.CurrentSlot), From: int(source.shard), To: int(target.shard)}.Apply(owned)
+		}
+
+		if resharding.SlotsToMove == 0 {
+			resharding.SlotsToMove = int32(redisclient.SlotsToMove(owned, int(resharding.ToShards)))
+		}
+
+		deadline := time.Now().Add(reshardingBudget)
+		for time.Now().Before(deadline) {
+			move, ok := redisclient.NextSlotMove(owned, int(resharding.ToShards))
+			if !ok {
+				break
+			}
+			slot := int32(move.Slot)
+			resharding.CurrentSlot = &slot
+			resharding.SourceNodeID = masters[move.From].myself.ID
+			resharding.TargetNodeID = masters[move.To].myself.ID
+			if err := r.migrateSlot(ctx, redis, topology, move.Slot, masters[move.From], masters[move.To], options); err != nil {
+				return false, err
+			}
+			move.Apply(owned)
+		}
+
+		if _, ok := redisclient.NextSlotMove(owned, int(resharding.ToShards)); ok {
+			logger.Info("Resharding in progress", "slotsMoved", resharding.SlotsMoved, "slotsToMove", resharding.SlotsToMove)
+			return false, nil
+		}
+		if resharding.FromShards <= resharding.ToShards {
+			logger.Info("Cluster resharding completed")
+			redis.Status.Cluster.Resharding = nil
+			return true, nil
+		}
+		logger.Info("Slots moved, removing drained shards")
+		resharding.Step = cachev1alpha1.ReshardingStepRemovingShards
+
+	case cachev1alpha1.ReshardingStepRemovingShards:
+		// Drained nodes are forgotten by the remaining nodes once they fail
+		for shard := resharding.ToShards; shard < resharding.FromShards; shard++ {
+			statefulSet := &appsv1.StatefulSet{}
+			statefulSet.SetName(metadata.RedisClusterShardName(redis.Name, shard))
+			statefulSet.SetNamespace(redis.Namespace)
+			if err := r.Delete(ctx, statefulSet); client.IgnoreNotFound(err) != nil {
+				return false, err
+			}
+		}
+		logger.Info("Cluster resharding completed")
+		redis.Status.Cluster.Resharding = nil
+		return true, nil
+	}
+	return false, nil
+}
+
+// migrateSlot moves keys of the slot from source to target master and assigns
+// the slot to target. Steps follow the order required by Redis Cluster, so the
+// slot is served during the migration and the procedure can be safely repeated.
+func (r *RedisReconciler) migrateSlot(ctx context.Context, redis *cachev1alpha1.Redis, topology *clusterTopology, slot int, source *clusterNode, target *clusterNode, options redisclient.Options) error {
+	resharding := redis.Status.Cluster.Resharding
+
+	if err := target.client.ClusterSetSlot(ctx, slot, "IMPORTING", source.myself.ID); err != nil {
+		return fmt.Errorf("failed to import slot %d to %s: %w", slot, target.pod.Name, err)
+	}
+	if err := source.client.ClusterSetSlot(ctx, slot, "MIGRATING", target.myself.ID); err != nil {
+		return fmt.Errorf("failed to migrate slot %d from %s: %w", slot, source.pod.Name, err)
+	}
+
+	for {
+		keys, err := source.client.ClusterGetKeysInSlot(ctx, slot, migrateBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			break
+		}
+		if err := source.client.Migrate(ctx, target.pod.Status.PodIP, redisPort, migrateTimeout, options.Password, keys); err != nil {
+			return fmt.Errorf("failed to migrate keys of slot %d to %s: %w", slot, target.pod.Name, err)
+		}
+		resharding.KeysMoved += int64(len(keys))
+	}
+
+	// Target has to learn about the new owner first, otherwise clients are
+	// redirected back to the source
+	if err := target.client.ClusterSetSlot(ctx, slot, "NODE", target.myself.ID); err != nil {
+		return err
+	}
+	if err := source.client.ClusterSetSlot(ctx, slot, "NODE", target.myself.ID); err != nil {
+		return err
+	}
+	for _, node := range topology.nodes {
+		if node == source || node == target || !node.myself.IsMaster() {
+			continue
+		}
+		if err := node.client.ClusterSetSlot(ctx, slot, "NODE", target.myself.ID); err != nil {
+			// Ownership is propagated by gossip anyway
+			log.FromContext(ctx).Error(err, "Failed to announce slot owner", "pod", node.pod.Name, "slot", slot)
+		}
+	}
+
+	resharding.SlotsMoved++
+	resharding.CurrentSlot = nil
+	resharding.SourceNodeID = ""
+	resharding.TargetNodeID = ""
+	return nil
+}
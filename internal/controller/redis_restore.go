@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	"github.com/avekrivoy/redis-operator/internal/metadata"
+	redisclient "github.com/avekrivoy/redis-operator/internal/redis"
+)
+
+const (
+	restoreRequeuePeriod = 5 * time.Second
+	// Name of init container of master pods restoring RDB file
+	restoreContainerName = "restore"
+)
+
+// reconcileRestore records progress of restore from RDB file in status until
+// master has loaded it. Replicas are not started and master Service has no
+// ready endpoints before that, as master answers PING with LOADING. It
+// returns true while the restore is in progress.
+func (r *RedisReconciler) reconcileRestore(ctx context.Context, redis *cachev1alpha1.Redis) (bool, error) {
+	if !redis.IsRestoring() || redis.Spec.Mode == cachev1alpha1.ModeCluster {
+		return false, nil
+	}
+
+	previous := redis.Status.Restore.DeepCopy()
+	if redis.Status.Restore == nil {
+		redis.Status.Restore = &cachev1alpha1.RedisRestoreStatus{
+			Phase:     cachev1alpha1.RestorePhaseDownloading,
+			Message:   fmt.Sprintf("Downloading %s", redis.Spec.Restore.Location),
+			StartTime: metav1.Now(),
+		}
+	}
+
+	pods, err := listPods(ctx, r.Client, redis, metadata.LabelSelector(redis.Name, metadata.RedisMasterComponent()))
+	if err != nil {
+		return false, err
+	}
+	if err := r.observeRestore(ctx, redis, pods); err != nil {
+		return false, err
+	}
+
+	status := redis.Status.Restore
+	if status.Phase == cachev1alpha1.RestorePhaseCompleted {
+		log.FromContext(ctx).Info("Restored RDB file", "location", redis.Spec.Restore.Location)
+	}
+	if !equality.Semantic.DeepEqual(previous, status) {
+		if err := r.Status().Update(ctx, redis); err != nil {
+			return false, err
+		}
+	}
+	return status.Phase != cachev1alpha1.RestorePhaseCompleted, nil
+}
+
+// observeRestore sets restore phase from the state of master pods: the init
+// container downloads the file, then Redis loads it and becomes ready
+func (r *RedisReconciler) observeRestore(ctx context.Context, redis *cachev1alpha1.Redis, pods []corev1.Pod) error {
+	status := redis.Status.Restore
+	if ready := readyPods(pods); len(ready) > 0 {
+		now := metav1.Now()
+		status.Phase = cachev1alpha1.RestorePhaseCompleted
+		status.Message = fmt.Sprintf("Master %s serves dataset of %s", ready[0].Name, redis.Spec.Restore.Location)
+		status.CompletionTime = &now
+		return nil
+	}
+
+	for _, pod := range pods {
+		restore := initContainerStatus(&pod, restoreContainerName)
+		if restore == nil {
+			continue
+		}
+		if terminated := restore.State.Terminated; terminated != nil && terminated.ExitCode == 0 {
+			status.Phase = cachev1alpha1.RestorePhaseLoading
+			status.Message = fmt.Sprintf("Master %s is loading the dataset", pod.Name)
+			if progress := r.loadingProgress(ctx, redis, &pod); progress != "" {
+				status.Message += ", " + progress
+			}
+			return nil
+		}
+		// Failed restore is retried by the kubelet, the phase shows the last failure until it succeeds
+		if terminated := restore.LastTerminationState.Terminated; terminated != nil && terminated.ExitCode != 0 && restore.State.Running == nil {
+			status.Phase = cachev1alpha1.RestorePhaseFailed
+			status.Message = fmt.Sprintf("Restore in %s failed: %s", pod.Name, strings.TrimSpace(terminated.Message))
+			return nil
+		}
+		status.Phase = cachev1alpha1.RestorePhaseDownloading
+		status.Message = fmt.Sprintf("Master %s is downloading %s", pod.Name, redis.Spec.Restore.Location)
+		return nil
+	}
+	return nil
+}
+
+// loadingProgress returns percentage of the dataset loaded by Redis in the
+// pod, empty if it can not be read
+func (r *RedisReconciler) loadingProgress(ctx context.Context, redis *cachev1alpha1.Redis, pod *corev1.Pod) string {
+	if pod.Status.PodIP == "" {
+		return ""
+	}
+	options, err := connectionOptions(ctx, r.Client, redis)
+	if err != nil {
+		return ""
+	}
+	redisClient, err := redisclient.Dial(ctx, podAddress(pod), options)
+	if err != nil {
+		return ""
+	}
+	defer redisClient.Close()
+	persistence, err := redisClient.Info(ctx, "persistence")
+	if err != nil || persistence["loading"] != "1" {
+		return ""
+	}
+	return fmt.Sprintf("%s%% loaded", persistence["loading_loaded_perc"])
+}
+
+// initContainerStatus returns status of init container of the pod or nil
+func initContainerStatus(pod *corev1.Pod, name string) *corev1.ContainerStatus {
+	for i := range pod.Status.InitContainerStatuses {
+		if pod.Status.InitContainerStatuses[i].Name == name {
+			return &pod.Status.InitContainerStatuses[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	"github.com/avekrivoy/redis-operator/internal/metadata"
+	redisclient "github.com/avekrivoy/redis-operator/internal/redis"
+	resources "github.com/avekrivoy/redis-operator/internal/resources"
+)
+
+// Failover may happen at any moment, so Sentinel mode is reconciled periodically
+const sentinelRequeuePeriod = 10 * time.Second
+
+// reconcileSentinel keeps Sentinels monitoring the current master, attaches
+// stray masters (e.g. restarted master pod) to it and labels Redis pods with
+// their role, so master Service always points to the pod promoted by Sentinel.
+func (r *RedisReconciler) reconcileSentinel(ctx context.Context, redis *cachev1alpha1.Redis) error {
+	logger := log.FromContext(ctx)
+
+	options, err := connectionOptions(ctx, r.Client, redis)
+	if err != nil {
+		return err
+	}
+
+	topology, err := inspectTopology(ctx, r.Client, redis, options)
+	if err != nil {
+		return err
+	}
+	reachable := 0
+	for _, state := range topology.pods {
+		if state.reachable() {
+			reachable++
+		} else if state.err != errPodNotReady {
+			logger.Error(state.err, "Failed to inspect Redis pod", "pod", state.pod.Name)
+		}
+	}
+	if reachable == 0 {
+		return nil
+	}
+
+	sentinelPods, err := listPods(ctx, r.Client, redis, metadata.LabelSelector(redis.Name, metadata.RedisSentinelComponent()))
+	if err != nil {
+		return err
+	}
+	sentinelPods = readyPods(sentinelPods)
+
+	masterIP := r.sentinelMaster(ctx, redis, sentinelPods, topology, options)
+	if masterIP == "" {
+		masterIP = electMaster(topology)
+	}
+	if masterIP == "" {
+		return fmt.Errorf("no Redis pod is available to become master")
+	}
+
+	for _, pod := range sentinelPods {
+		if err := r.configureSentinel(ctx, redis, &pod, masterIP, topology, options); err != nil {
+			logger.Error(err, "Failed to configure Sentinel", "pod", pod.Name)
+		}
+	}
+
+	for _, state := range topology.masters() {
+		if state.pod.Status.PodIP == masterIP {
+			continue
+		}
+		logger.Info("Attaching stray master to current master", "pod", state.pod.Name, "master", masterIP)
+		if err := r.attachReplica(ctx, &state.pod, options, masterIP); err != nil {
+			logger.Error(err, "Failed to attach replica", "pod", state.pod.Name)
+		}
+	}
+
+	for i := range topology.pods {
+		pod := &topology.pods[i].pod
+		role := metadata.RoleReplica
+		if pod.Status.PodIP == masterIP {
+			role = metadata.RoleMaster
+		}
+		if err := r.setPodRole(ctx, pod, role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sentinelMaster returns the master most Sentinels agree on. Addresses that
+// do not belong to running Redis pods are ignored.
+func (r *RedisReconciler) sentinelMaster(ctx context.Context, redis *cachev1alpha1.Redis, sentinelPods []corev1.Pod, topology *redisTopology, options redisclient.Options) string {
+	votes := map[string]int{}
+	master := ""
+	for _, pod := range sentinelPods {
+		addr, err := r.sentinelMasterAddr(ctx, redis, &pod, options)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "Failed to get master from Sentinel", "pod", pod.Name)
+			continue
+		}
+		if topology.byIP(addr) == nil {
+			continue
+		}
+		votes[addr]++
+		if votes[addr] > votes[master] {
+			master = addr
+		}
+	}
+	return master
+}
+
+// electMaster chooses master when no Sentinel monitors one yet: the master
+// with most connected replicas, preferring pods of the master component.
+func electMaster(topology *redisTopology) string {
+	master := ""
+	bestReplicas, bestIsMasterComponent := -1, false
+	for _, state := range topology.masters() {
+		replicas := len(state.role.Replicas)
+		isMasterComponent := state.pod.Labels["app.kubernetes.io/component"] == metadata.RedisMasterComponent()
+		if replicas > bestReplicas || (replicas == bestReplicas && isMasterComponent && !bestIsMasterComponent) {
+			master = state.pod.Status.PodIP
+			bestReplicas, bestIsMasterComponent = replicas, isMasterComponent
+		}
+	}
+	return master
+}
+
+func (r *RedisReconciler) sentinelMasterAddr(ctx context.Context, redis *cachev1alpha1.Redis, pod *corev1.Pod, options redisclient.Options) (string, error) {
+	sentinelClient, err := redisclient.Dial(ctx, sentinelAddress(pod), sentinelOptions(options))
+	if err != nil {
+		return "", err
+	}
+	defer sentinelClient.Close()
+
+	return sentinelClient.SentinelMasterAddr(ctx, redis.Spec.Sentinel.MasterSet)
+}
+
+// configureSentinel makes Sentinel monitor masterIP and applies monitoring options
+func (r *RedisReconciler) configureSentinel(ctx context.Context, redis *cachev1alpha1.Redis, pod *corev1.Pod, masterIP string, topology *redisTopology, options redisclient.Options) error {
+	spec := redis.Spec.Sentinel
+	sentinelClient, err := redisclient.Dial(ctx, sentinelAddress(pod), sentinelOptions(options))
+	if err != nil {
+		return err
+	}
+	defer sentinelClient.Close()
+
+	addr, err := sentinelClient.SentinelMasterAddr(ctx, spec.MasterSet)
+	if err != nil {
+		return err
+	}
+
+	// Sentinels monitoring a known pod are left alone, they may be in the middle of failover
+	if topology.byIP(addr) == nil {
+		log.FromContext(ctx).Info("Configuring Sentinel", "pod", pod.Name, "master", masterIP)
+		if addr != "" {
+			if err := sentinelClient.SentinelRemove(ctx, spec.MasterSet); err != nil {
+				return err
+			}
+		}
+		if err := sentinelClient.SentinelMonitor(ctx, spec.MasterSet, masterIP, redisPort, spec.Quorum); err != nil {
+			return err
+		}
+	}
+
+	monitorOptions := map[string]string{
+		"quorum":                  strconv.Itoa(int(spec.Quorum)),
+		"down-after-milliseconds": strconv.Itoa(int(spec.DownAfterMilliseconds)),
+		"failover-timeout":        strconv.Itoa(int(spec.FailoverTimeout)),
+		// Empty password clears auth-pass when authentication gets disabled
+		"auth-pass": options.Password,
+	}
+	for option, value := range monitorOptions {
+		if err := sentinelClient.SentinelSet(ctx, spec.MasterSet, option, value); err != nil {
+			return err
+		}
+	}
+
+	// Restarted Sentinels come back with new addresses, the old ones are still
+	// counted by peers and may prevent failover authorization. RESET drops them.
+	peers, err := sentinelClient.SentinelPeers(ctx, spec.MasterSet)
+	if err != nil {
+		return err
+	}
+	if int32(peers) > spec.Count-1 {
+		log.FromContext(ctx).Info("Resetting Sentinel with stale peers", "pod", pod.Name, "peers", peers)
+		return sentinelClient.SentinelReset(ctx, spec.MasterSet)
+	}
+	return nil
+}
+
+func (r *RedisReconciler) attachReplica(ctx context.Context, pod *corev1.Pod, options redisclient.Options, masterIP string) error {
+	redisClient, err := redisclient.Dial(ctx, podAddress(pod), options)
+	if err != nil {
+		return err
+	}
+	defer redisClient.Close()
+
+	if err := redisClient.ConfigSet(ctx, "masterauth", options.Password); err != nil {
+		return err
+	}
+	return redisClient.ReplicaOf(ctx, masterIP, redisPort)
+}
+
+func (r *RedisReconciler) setPodRole(ctx context.Context, pod *corev1.Pod, role string) error {
+	if pod.Labels[metadata.RoleLabel] == role {
+		return nil
+	}
+	patch := client.MergeFrom(pod.DeepCopy())
+	if pod.Labels == nil {
+		pod.Labels = map[string]string{}
+	}
+	pod.Labels[metadata.RoleLabel] = role
+	return r.Patch(ctx, pod, patch)
+}
+
+func sentinelAddress(pod *corev1.Pod) string {
+	return net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(resources.RedisSentinelPort))
+}
+
+// sentinelOptions returns options of connections to Sentinel, which does not require password
+func sentinelOptions(options redisclient.Options) redisclient.Options {
+	return redisclient.Options{TLS: options.TLS}
+}
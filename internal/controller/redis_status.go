@@ -0,0 +1,350 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	"github.com/avekrivoy/redis-operator/internal/metadata"
+	redisclient "github.com/avekrivoy/redis-operator/internal/redis"
+	resources "github.com/avekrivoy/redis-operator/internal/resources"
+)
+
+// observedState is what the operator sees running compared to the spec
+type observedState struct {
+	desiredMasters  int32
+	desiredReplicas int32
+	readyMasters    int32
+	readyReplicas   int32
+	currentMaster   string
+	// replicationHealthy is the ReplicationHealthy condition status with its reason and message
+	replicationHealthy bool
+	replicationReason  string
+	replicationMessage string
+}
+
+// updateStatus records observed state, endpoints and conditions in status.
+// reconcileErr is the error reconciliation failed with, it marks Redis degraded.
+func (r *RedisReconciler) updateStatus(ctx context.Context, redis *cachev1alpha1.Redis, reconcileErr error) error {
+	previous := redis.Status.DeepCopy()
+
+	var observed *observedState
+	var err error
+	if redis.Spec.Mode == cachev1alpha1.ModeCluster {
+		observed = observeCluster(redis)
+	} else {
+		observed, err = r.observeReplication(ctx, redis)
+		if err != nil {
+			return err
+		}
+	}
+
+	progressingReason, progressingMessage, err := r.progress(ctx, redis)
+	if err != nil {
+		return err
+	}
+
+	status := &redis.Status
+	status.ObservedGeneration = redis.Generation
+	status.ReadyMasters = observed.readyMasters
+	status.ReadyReplicas = observed.readyReplicas
+	status.CurrentMaster = observed.currentMaster
+	status.Endpoints = endpoints(redis)
+
+	setCondition(redis, cachev1alpha1.ConditionReplicationHealthy, observed.replicationHealthy, observed.replicationReason, observed.replicationMessage)
+
+	if redis.Spec.Common.Auth.IsEnabled() {
+		meta.RemoveStatusCondition(&status.Conditions, cachev1alpha1.ConditionAuthDisabled)
+	} else {
+		setCondition(redis, cachev1alpha1.ConditionAuthDisabled, true, "PasswordlessAccess",
+			"Password authentication is disabled, any client can connect to Redis. Use it only for development and testing")
+	}
+
+	builder := resources.RedisResourceBuilder{Instance: redis}
+	if exceeding := builder.MaxMemoryExceedingLimit(); len(exceeding) > 0 {
+		setCondition(redis, cachev1alpha1.ConditionMaxMemoryExceedsLimit, true, "MaxMemoryAboveLimit",
+			strings.Join(exceeding, "; ")+". Redis may be OOM-killed before it evicts keys")
+	} else {
+		meta.RemoveStatusCondition(&status.Conditions, cachev1alpha1.ConditionMaxMemoryExceedsLimit)
+	}
+
+	progressing := progressingReason != ""
+	if progressing {
+		setCondition(redis, cachev1alpha1.ConditionProgressing, true, progressingReason, progressingMessage)
+	} else {
+		setCondition(redis, cachev1alpha1.ConditionProgressing, false, "ReconcileComplete", "Redis matches the spec")
+	}
+
+	available := observed.readyMasters >= observed.desiredMasters && observed.readyReplicas >= observed.desiredReplicas
+	podsMessage := fmt.Sprintf("%d/%d masters and %d/%d replicas are ready",
+		observed.readyMasters, observed.desiredMasters, observed.readyReplicas, observed.desiredReplicas)
+
+	switch {
+	case reconcileErr != nil:
+		setCondition(redis, cachev1alpha1.ConditionDegraded, true, "ReconcileError", reconcileErr.Error())
+	case !available && !progressing:
+		setCondition(redis, cachev1alpha1.ConditionDegraded, true, "PodsUnavailable", podsMessage)
+	case !observed.replicationHealthy && !progressing:
+		setCondition(redis, cachev1alpha1.ConditionDegraded, true, observed.replicationReason, observed.replicationMessage)
+	default:
+		setCondition(redis, cachev1alpha1.ConditionDegraded, false, "AsExpected", "Redis is reconciled")
+	}
+
+	switch {
+	case reconcileErr != nil:
+		setCondition(redis, cachev1alpha1.ConditionReady, false, "ReconcileError", reconcileErr.Error())
+	case !available:
+		setCondition(redis, cachev1alpha1.ConditionReady, false, "PodsNotReady", podsMessage)
+	case !observed.replicationHealthy:
+		setCondition(redis, cachev1alpha1.ConditionReady, false, observed.replicationReason, observed.replicationMessage)
+	default:
+		setCondition(redis, cachev1alpha1.ConditionReady, true, "Ready", podsMessage)
+	}
+
+	if equality.Semantic.DeepEqual(previous, status) {
+		return nil
+	}
+	return r.Status().Update(ctx, redis)
+}
+
+func setCondition(redis *cachev1alpha1.Redis, conditionType string, status bool, reason string, message string) {
+	conditionStatus := metav1.ConditionFalse
+	if status {
+		conditionStatus = metav1.ConditionTrue
+	}
+	meta.SetStatusCondition(&redis.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		ObservedGeneration: redis.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// observeReplication inspects master and replica pods. In Sentinel mode there
+// is a single master and all other pods are its replicas.
+func (r *RedisReconciler) observeReplication(ctx context.Context, redis *cachev1alpha1.Redis) (*observedState, error) {
+	observed := &observedState{
+		desiredMasters:  redis.Spec.Master.Count,
+		desiredReplicas: redis.Spec.Replica.Count,
+	}
+	if redis.Spec.Sentinel.Enabled && redis.Spec.Master.Count > 0 {
+		observed.desiredMasters = 1
+		observed.desiredReplicas = redis.Spec.Master.Count + redis.Spec.Replica.Count - 1
+	}
+
+	options, err := connectionOptions(ctx, r.Client, redis)
+	if k8serrors.IsNotFound(err) {
+		observed.replicationReason = "AuthSecretMissing"
+		observed.replicationMessage = "Secret with Redis password or TLS certificate does not exist"
+		return observed, nil
+	} else if err != nil {
+		return nil, err
+	}
+	topology, err := inspectTopology(ctx, r.Client, redis, options)
+	if err != nil {
+		return nil, err
+	}
+
+	outOfSync := []string{}
+	for _, state := range topology.pods {
+		if !state.reachable() {
+			continue
+		}
+		if state.role.IsMaster() {
+			observed.readyMasters++
+		} else if state.replication.InSync() {
+			observed.readyReplicas++
+		} else {
+			outOfSync = append(outOfSync, state.pod.Name)
+		}
+	}
+	if master := topology.master(); master != nil {
+		observed.currentMaster = master.pod.Name
+	}
+
+	switch {
+	case observed.currentMaster == "":
+		observed.replicationReason = "NoMaster"
+		observed.replicationMessage = "No pod is running as master"
+	case redis.Spec.Sentinel.Enabled && observed.readyMasters > 1:
+		observed.replicationReason = "MultipleMasters"
+		observed.replicationMessage = fmt.Sprintf("%d pods are running as master", observed.readyMasters)
+	case len(outOfSync) > 0:
+		observed.replicationReason = "ReplicasOutOfSync"
+		observed.replicationMessage = fmt.Sprintf("Replicas %v are not in sync with master", outOfSync)
+	default:
+		observed.replicationHealthy = true
+		observed.replicationReason = "ReplicasInSync"
+		observed.replicationMessage = fmt.Sprintf("%d replicas are in sync with master", observed.readyReplicas)
+	}
+	return observed, nil
+}
+
+// observeCluster derives observed state from slot ownership recorded by cluster reconciliation
+func observeCluster(redis *cachev1alpha1.Redis) *observedState {
+	spec := redis.Spec.Cluster
+	observed := &observedState{
+		desiredMasters:  spec.Shards,
+		desiredReplicas: spec.Shards * spec.ReplicasPerShard,
+	}
+
+	clusterStatus := redis.Status.Cluster
+	if clusterStatus == nil {
+		observed.replicationReason = "Bootstrapping"
+		observed.replicationMessage = "Cluster is not bootstrapped yet"
+		return observed
+	}
+
+	missing := []int32{}
+	for _, shard := range clusterStatus.Shards {
+		if shard.Master != "" {
+			observed.readyMasters++
+		}
+		observed.readyReplicas += shard.Replicas
+		if shard.Replicas < spec.ReplicasPerShard {
+			missing = append(missing, shard.Index)
+		}
+	}
+
+	switch {
+	case clusterStatus.State != "ok":
+		observed.replicationReason = "ClusterFail"
+		observed.replicationMessage = fmt.Sprintf("Cluster state is %q", clusterStatus.State)
+	case clusterStatus.SlotsAssigned != redisclient.ClusterSlots:
+		observed.replicationReason = "SlotsNotAssigned"
+		observed.replicationMessage = fmt.Sprintf("%d of %d slots are assigned", clusterStatus.SlotsAssigned, redisclient.ClusterSlots)
+	case len(missing) > 0:
+		observed.replicationReason = "ReplicasMissing"
+		observed.replicationMessage = fmt.Sprintf("Shards %v have less than %d replicas", missing, spec.ReplicasPerShard)
+	default:
+		observed.replicationHealthy = true
+		observed.replicationReason = "ClusterOk"
+		observed.replicationMessage = "All slots are served and replicated"
+	}
+	return observed
+}
+
+// progress returns reason and message of Progressing condition, reason is
+// empty when no change is in progress
+func (r *RedisReconciler) progress(ctx context.Context, redis *cachev1alpha1.Redis) (string, string, error) {
+	if restore := redis.Status.Restore; redis.IsRestoring() && restore != nil {
+		return "Restoring", fmt.Sprintf("Restoring from %s: %s", redis.Spec.Restore.Location, restore.Phase), nil
+	}
+	if clone := redis.Status.Clone; redis.IsCloning() && clone != nil {
+		return "Cloning", fmt.Sprintf("Cloning from %s: %s", redis.CloneSource(), clone.Phase), nil
+	}
+	if external := redis.Status.ExternalMaster; redis.IsReplicatingExternalMaster() && external != nil {
+		return "ReplicatingExternalMaster", fmt.Sprintf("Replicating from external master %s: %s", redis.Spec.ExternalMaster.Host, external.Phase), nil
+	}
+
+	for _, migration := range redis.Status.Migrations {
+		if migration.Phase != cachev1alpha1.MigrationPhaseCompleted {
+			return "Migrating", fmt.Sprintf("Migrating %s from %s to %s: %s", migration.Component, migration.From, migration.To, migration.Phase), nil
+		}
+	}
+
+	if auth := redis.Status.Auth; auth != nil && auth.Rotation != nil {
+		return "RotatingPassword", fmt.Sprintf("Rotating password: %s", auth.Rotation.Phase), nil
+	}
+
+	if redis.Spec.Mode == cachev1alpha1.ModeCluster {
+		clusterStatus := redis.Status.Cluster
+		if clusterStatus == nil || clusterStatus.SlotsAssigned == 0 {
+			return "Bootstrapping", "Cluster is being bootstrapped", nil
+		}
+		if resharding := clusterStatus.Resharding; resharding != nil {
+			return "Resharding", fmt.Sprintf("Resharding from %d to %d shards: %d/%d slots moved",
+				resharding.FromShards, resharding.ToShards, resharding.SlotsMoved, resharding.SlotsToMove), nil
+		}
+	}
+
+	rollingOut, err := r.rollingOutWorkloads(ctx, redis)
+	if err != nil {
+		return "", "", err
+	}
+	if len(rollingOut) > 0 {
+		return "RollingOut", fmt.Sprintf("Workloads %v are being rolled out", rollingOut), nil
+	}
+	return "", "", nil
+}
+
+// rollingOutWorkloads returns names of workloads whose pods are not all updated and ready
+func (r *RedisReconciler) rollingOutWorkloads(ctx context.Context, redis *cachev1alpha1.Redis) ([]string, error) {
+	selector := client.MatchingLabels{"app.kubernetes.io/name": redis.Name}
+	rollingOut := []string{}
+
+	deployments := &appsv1.DeploymentList{}
+	if err := r.List(ctx, deployments, client.InNamespace(redis.Namespace), selector); err != nil {
+		return nil, err
+	}
+	for _, deployment := range deployments.Items {
+		if !metav1.IsControlledBy(&deployment, redis) {
+			continue
+		}
+		replicas := int32(1)
+		if deployment.Spec.Replicas != nil {
+			replicas = *deployment.Spec.Replicas
+		}
+		if deployment.Status.ObservedGeneration < deployment.Generation ||
+			deployment.Status.UpdatedReplicas < replicas ||
+			deployment.Status.ReadyReplicas < replicas ||
+			deployment.Status.Replicas > replicas {
+			rollingOut = append(rollingOut, deployment.Name)
+		}
+	}
+
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := r.List(ctx, statefulSets, client.InNamespace(redis.Namespace), selector); err != nil {
+		return nil, err
+	}
+	for _, statefulSet := range statefulSets.Items {
+		if !metav1.IsControlledBy(&statefulSet, redis) {
+			continue
+		}
+		replicas := int32(1)
+		if statefulSet.Spec.Replicas != nil {
+			replicas = *statefulSet.Spec.Replicas
+		}
+		if statefulSet.Status.ObservedGeneration < statefulSet.Generation ||
+			statefulSet.Status.UpdatedReplicas < replicas ||
+			statefulSet.Status.ReadyReplicas < replicas ||
+			statefulSet.Status.Replicas > replicas {
+			rollingOut = append(rollingOut, statefulSet.Name)
+		}
+	}
+
+	return rollingOut, nil
+}
+
+// endpoints returns in-cluster addresses of Services deployed for Redis
+func endpoints(redis *cachev1alpha1.Redis) *cachev1alpha1.RedisEndpoints {
+	address := func(component string, port int) string {
+		return fmt.Sprintf("%s.%s.svc:%d", metadata.RedisServiceName(redis.Name, component), redis.Namespace, port)
+	}
+
+	if redis.Spec.Mode == cachev1alpha1.ModeCluster {
+		return &cachev1alpha1.RedisEndpoints{
+			Cluster: address(metadata.RedisClusterComponent(), redisPort),
+		}
+	}
+
+	endpoints := &cachev1alpha1.RedisEndpoints{
+		Master: address(metadata.RedisMasterComponent(), redisPort),
+	}
+	if redis.Spec.Replica.Count > 0 {
+		endpoints.Replica = address(metadata.RedisReplicaComponent(), redisPort)
+	}
+	if redis.Spec.Sentinel.Enabled {
+		endpoints.Sentinel = address(metadata.RedisSentinelComponent(), resources.RedisSentinelPort)
+	}
+	return endpoints
+}
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	"github.com/avekrivoy/redis-operator/internal/metadata"
+	redisclient "github.com/avekrivoy/redis-operator/internal/redis"
+	"github.com/avekrivoy/redis-operator/internal/resources"
+)
+
+// tlsCheckPeriod limits time until served certificates are checked again
+const tlsCheckPeriod = time.Hour
+
+// connectionOptions returns options of connections to Redis pods
+func connectionOptions(ctx context.Context, c client.Reader, redis *cachev1alpha1.Redis) (redisclient.Options, error) {
+	password, err := redisPassword(ctx, c, redis)
+	if err != nil {
+		return redisclient.Options{}, err
+	}
+	tlsConfig, err := redisTLSConfig(ctx, c, redis)
+	if err != nil {
+		return redisclient.Options{}, err
+	}
+	return redisclient.Options{Password: password, TLS: tlsConfig}, nil
+}
+
+// redisTLSConfig returns TLS configuration of connections to Redis and
+// Sentinel pods, nil if TLS is disabled. Pods are reached by IP, so only the
+// certificate chain is verified, not the host name. The serving certificate is
+// presented as client certificate when clients have to authenticate.
+func redisTLSConfig(ctx context.Context, c client.Reader, redis *cachev1alpha1.Redis) (*tls.Config, error) {
+	if !redis.Spec.Common.TLS.Enabled {
+		return nil, nil
+	}
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{
+		Name:      resources.TLSSecretName(redis),
+		Namespace: redis.Namespace,
+	}, secret); err != nil {
+		return nil, err
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(secret.Data[resources.TLSCAKey]) {
+		return nil, fmt.Errorf("secret %s has no valid %s", secret.Name, resources.TLSCAKey)
+	}
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// Chain is verified by VerifyConnection without host name
+		InsecureSkipVerify: true,
+		VerifyConnection: func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return errors.New("server presented no certificate")
+			}
+			intermediates := x509.NewCertPool()
+			for _, cert := range state.PeerCertificates[1:] {
+				intermediates.AddCert(cert)
+			}
+			_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+				Roots:         roots,
+				Intermediates: intermediates,
+			})
+			return err
+		},
+	}
+	if redis.Spec.Common.TLS.AuthClients {
+		certificate, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate from %s: %w", secret.Name, err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+	return config, nil
+}
+
+// reconcileTLS makes Redis pods serve the certificate of the TLS secret and
+// records it in status. Redis reloads certificate files when any TLS option is
+// set, Sentinel pods are restarted by the resource builders instead. It returns
+// period after which the certificate has to be checked again.
+func (r *RedisReconciler) reconcileTLS(ctx context.Context, redis *cachev1alpha1.Redis) (time.Duration, error) {
+	tlsSpec := redis.Spec.Common.TLS
+	if !tlsSpec.Enabled {
+		redis.Status.TLS = nil
+		return 0, nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{
+		Name:      resources.TLSSecretName(redis),
+		Namespace: redis.Namespace,
+	}, secret); err != nil {
+		return 0, err
+	}
+	certificate, err := resources.ParseCertificate(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse certificate of %s: %w", secret.Name, err)
+	}
+	status := &cachev1alpha1.RedisTLSStatus{
+		SerialNumber: certificate.SerialNumber.Text(16),
+		NotAfter:     &metav1.Time{Time: certificate.NotAfter},
+	}
+	// Serial number restarts Sentinel pods, so it is persisted right away
+	if !equality.Semantic.DeepEqual(redis.Status.TLS, status) {
+		redis.Status.TLS = status
+		if err := r.Status().Update(ctx, redis); err != nil {
+			return 0, err
+		}
+	}
+
+	options, err := connectionOptions(ctx, r.Client, redis)
+	if err != nil {
+		return 0, err
+	}
+	for _, component := range []string{metadata.RedisMasterComponent(), metadata.RedisReplicaComponent(), metadata.RedisClusterComponent()} {
+		pods, err := listPods(ctx, r.Client, redis, metadata.LabelSelector(redis.Name, component))
+		if err != nil {
+			return 0, err
+		}
+		for _, pod := range readyPods(pods) {
+			if err := reloadCertificate(ctx, &pod, options, certificate); err != nil {
+				// Mounted secret may not be updated yet, certificate is checked again later
+				log.FromContext(ctx).Error(err, "Failed to reload certificate", "pod", pod.Name)
+			}
+		}
+	}
+
+	requeueAfter := tlsCheckPeriod
+	if tlsSpec.ExistingSecret == "" {
+		// Resource builders renew the generated certificate once it is in renewal window
+		if renewal := time.Until(certificate.NotAfter.Add(-tlsSpec.GetRenewBefore())); renewal > 0 && renewal < requeueAfter {
+			requeueAfter = renewal
+		}
+	}
+	return requeueAfter, nil
+}
+
+// reloadCertificate makes Redis pod load certificate files again if it serves
+// a certificate other than the current one
+func reloadCertificate(ctx context.Context, pod *corev1.Pod, options redisclient.Options, certificate *x509.Certificate) error {
+	redisClient, err := redisclient.Dial(ctx, podAddress(pod), options)
+	if err != nil {
+		return err
+	}
+	defer redisClient.Close()
+
+	served := redisClient.PeerCertificate()
+	if served != nil && served.SerialNumber.Cmp(certificate.SerialNumber) == 0 {
+		return nil
+	}
+	log.FromContext(ctx).Info("Reloading TLS certificate", "pod", pod.Name)
+	return redisClient.ConfigSet(ctx, "tls-cert-file", resources.RedisTLSPath+"/"+corev1.TLSCertKey)
+}
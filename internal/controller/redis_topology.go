@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	"github.com/avekrivoy/redis-operator/internal/metadata"
+	redisclient "github.com/avekrivoy/redis-operator/internal/redis"
+)
+
+var errPodNotReady = errors.New("pod is not ready")
+
+// redisPodState is replication state of a Redis pod as reported by the pod
+// itself. Role and master of the pod come from ROLE, state of the link to
+// master and offsets from INFO replication.
+type redisPodState struct {
+	pod         corev1.Pod
+	role        *redisclient.RoleInfo
+	replication *redisclient.ReplicationInfo
+	// err is set if the pod is not ready or could not be inspected
+	err error
+}
+
+// reachable checks if the pod answered all inspection commands
+func (state *redisPodState) reachable() bool {
+	return state.err == nil && state.role != nil && state.replication != nil
+}
+
+// redisTopology is replication topology of master and replica pods
+type redisTopology struct {
+	pods []redisPodState
+}
+
+// byIP returns state of the reachable pod with given IP
+func (t *redisTopology) byIP(ip string) *redisPodState {
+	for i := range t.pods {
+		if t.pods[i].reachable() && t.pods[i].pod.Status.PodIP == ip {
+			return &t.pods[i]
+		}
+	}
+	return nil
+}
+
+// masters returns reachable pods running as master
+func (t *redisTopology) masters() []*redisPodState {
+	masters := []*redisPodState{}
+	for i := range t.pods {
+		if t.pods[i].reachable() && t.pods[i].role.IsMaster() {
+			masters = append(masters, &t.pods[i])
+		}
+	}
+	return masters
+}
+
+// master returns the master most replicas replicate from. It is nil if no
+// pod runs as master.
+func (t *redisTopology) master() *redisPodState {
+	var master *redisPodState
+	for _, candidate := range t.masters() {
+		if master == nil || t.replicasOf(candidate) > t.replicasOf(master) {
+			master = candidate
+		}
+	}
+	return master
+}
+
+// replicasOf returns number of reachable replicas configured to replicate from master
+func (t *redisTopology) replicasOf(master *redisPodState) int {
+	replicas := 0
+	for i := range t.pods {
+		state := &t.pods[i]
+		if state.reachable() && state.role.Role == redisclient.RoleReplica && state.role.MasterHost == master.pod.Status.PodIP {
+			replicas++
+		}
+	}
+	return replicas
+}
+
+// inspectTopology asks every master and replica pod of Redis instance for its
+// role and replication state with PING, ROLE and INFO replication. Pods which
+// are not ready or fail to answer are kept with err set.
+func inspectTopology(ctx context.Context, c client.Reader, redis *cachev1alpha1.Redis, options redisclient.Options) (*redisTopology, error) {
+	topology := &redisTopology{}
+	for _, component := range []string{metadata.RedisMasterComponent(), metadata.RedisReplicaComponent()} {
+		pods, err := listPods(ctx, c, redis, metadata.LabelSelector(redis.Name, component))
+		if err != nil {
+			return nil, err
+		}
+		for _, pod := range pods {
+			topology.pods = append(topology.pods, inspectPod(ctx, pod, options))
+		}
+	}
+	return topology, nil
+}
+
+func inspectPod(ctx context.Context, pod corev1.Pod, options redisclient.Options) redisPodState {
+	state := redisPodState{pod: pod}
+	if !isPodReady(&pod) || pod.Status.PodIP == "" {
+		state.err = errPodNotReady
+		return state
+	}
+
+	redisClient, err := redisclient.Dial(ctx, podAddress(&pod), options)
+	if err != nil {
+		state.err = err
+		return state
+	}
+	defer redisClient.Close()
+
+	if state.err = redisClient.Ping(ctx); state.err != nil {
+		return state
+	}
+	if state.role, state.err = redisClient.Role(ctx); state.err != nil {
+		return state
+	}
+	state.replication, state.err = redisClient.Replication(ctx)
+	return state
+}
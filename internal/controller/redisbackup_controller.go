@@ -0,0 +1,306 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	redisclient "github.com/avekrivoy/redis-operator/internal/redis"
+	"github.com/avekrivoy/redis-operator/internal/storage"
+)
+
+const (
+	// backupRequeuePeriod is used while no Redis pod is ready to take a backup
+	backupRequeuePeriod = 10 * time.Second
+	// backupTimeout bounds taking and uploading a single snapshot
+	backupTimeout = time.Hour
+	// backupDeletionPollPeriod is how often a running backup checks it was not deleted
+	backupDeletionPollPeriod = 10 * time.Second
+)
+
+// RedisBackupReconciler reconciles a RedisBackup object
+type RedisBackupReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// running holds UIDs of backups transferred in background
+	running sync.Map
+}
+
+//+kubebuilder:rbac:groups=cache.assignment.yazio.com,resources=redisbackups,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=cache.assignment.yazio.com,resources=redisbackups/status,verbs=get;update;patch
+
+// Reconcile takes RDB snapshot of a Redis pod and uploads it to object
+// storage. Each RedisBackup is taken once, failed backups are not retried.
+// The transfer runs in background, so a large backup does not block others.
+func (r *RedisBackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	backup := &cachev1alpha1.RedisBackup{}
+	if err := r.Get(ctx, req.NamespacedName, backup); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if backup.IsFinished() {
+		return ctrl.Result{}, nil
+	}
+	if backup.Status.Phase == cachev1alpha1.BackupPhaseRunning {
+		if _, ok := r.running.Load(backup.UID); ok {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, r.failBackup(ctx, backup, "Backup was interrupted by restart of the operator")
+	}
+
+	redis := &cachev1alpha1.Redis{}
+	if err := r.Get(ctx, types.NamespacedName{Name: backup.Spec.Redis, Namespace: backup.Namespace}, redis); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, r.failBackup(ctx, backup, fmt.Sprintf("Redis %s does not exist", backup.Spec.Redis))
+		}
+		return ctrl.Result{}, err
+	}
+	if redis.Spec.Mode == cachev1alpha1.ModeCluster {
+		return ctrl.Result{}, r.failBackup(ctx, backup, "Backup of Redis in cluster mode is not supported")
+	}
+
+	objectStorage, err := backupStorage(ctx, r.Client, backup.Namespace, &backup.Spec.Storage)
+	if err != nil {
+		return ctrl.Result{}, r.failBackup(ctx, backup, err.Error())
+	}
+
+	options, err := connectionOptions(ctx, r.Client, redis)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	topology, err := inspectTopology(ctx, r.Client, redis, options)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	pod := backupPod(topology)
+	if pod == nil {
+		backup.Status.Phase = cachev1alpha1.BackupPhasePending
+		backup.Status.Message = "Waiting for a ready Redis pod"
+		return ctrl.Result{RequeueAfter: backupRequeuePeriod}, r.Status().Update(ctx, backup)
+	}
+
+	now := metav1.Now()
+	backup.Status.Phase = cachev1alpha1.BackupPhaseRunning
+	backup.Status.Message = "Taking RDB snapshot"
+	backup.Status.Pod = pod.Name
+	backup.Status.StartTime = &now
+	if err := r.Status().Update(ctx, backup); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	pod = pod.DeepCopy()
+	r.runBackup(ctx, backup.DeepCopy(), func(ctx context.Context, backup *cachev1alpha1.RedisBackup) error {
+		return takeBackup(ctx, backup, pod, options, objectStorage)
+	})
+	return ctrl.Result{}, nil
+}
+
+// runBackup takes the backup in background with take and records its result
+// in status. Backups still running when the operator stops are failed on its
+// next start.
+func (r *RedisBackupReconciler) runBackup(ctx context.Context, backup *cachev1alpha1.RedisBackup, take func(context.Context, *cachev1alpha1.RedisBackup) error) {
+	r.running.Store(backup.UID, true)
+	ctx = context.WithoutCancel(ctx)
+
+	go func() {
+		defer r.running.Delete(backup.UID)
+		logger := log.FromContext(ctx)
+
+		backupCtx, cancel := r.cancelOnDeletion(ctx, backup)
+		defer cancel()
+		if err := take(backupCtx, backup); err != nil {
+			logger.Error(err, "Backup failed", "pod", backup.Status.Pod)
+			backup.Status.Phase = cachev1alpha1.BackupPhaseFailed
+			backup.Status.Message = err.Error()
+		} else {
+			logger.Info("Backup completed", "pod", backup.Status.Pod, "location", backup.Status.Location, "size", backup.Status.Size)
+			backup.Status.Phase = cachev1alpha1.BackupPhaseCompleted
+			backup.Status.Message = ""
+		}
+		now := metav1.Now()
+		backup.Status.CompletionTime = &now
+
+		if err := r.updateBackgroundStatus(ctx, backup); err != nil {
+			logger.Error(err, "Failed to record backup result")
+		}
+	}()
+}
+
+// updateBackgroundStatus records status of the backup taken in background.
+// The backup may have been updated during the transfer, so the update is
+// retried on the latest version. Deleted backups are left as is.
+func (r *RedisBackupReconciler) updateBackgroundStatus(ctx context.Context, backup *cachev1alpha1.RedisBackup) error {
+	status := backup.Status
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current := &cachev1alpha1.RedisBackup{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(backup), current); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if current.UID != backup.UID || !current.DeletionTimestamp.IsZero() {
+			return nil
+		}
+		current.Status = status
+		return r.Status().Update(ctx, current)
+	})
+}
+
+// backupPod returns pod to take the snapshot from. Replicas in sync with
+// their master are preferred, so the master does not pay for forking the
+// background save. It is nil if no pod is reachable.
+func backupPod(topology *redisTopology) *corev1.Pod {
+	for i := range topology.pods {
+		state := &topology.pods[i]
+		if state.reachable() && state.replication.InSync() {
+			return &state.pod
+		}
+	}
+	if master := topology.master(); master != nil {
+		return &master.pod
+	}
+	return nil
+}
+
+// takeBackup streams RDB snapshot of the pod to object storage in parts, so
+// the snapshot is neither held in memory nor written to the local disk. Its
+// checksum is computed on the way. Details of the backup are recorded in status.
+func takeBackup(ctx context.Context, backup *cachev1alpha1.RedisBackup, pod *corev1.Pod, options redisclient.Options, objectStorage *storage.S3) error {
+	ctx, cancel := context.WithTimeout(ctx, backupTimeout)
+	defer cancel()
+
+	redisClient, err := redisclient.Dial(ctx, podAddress(pod), options)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", pod.Name, err)
+	}
+	defer redisClient.Close()
+	server, err := redisClient.Info(ctx, "server")
+	if err != nil {
+		return fmt.Errorf("failed to get version of %s: %w", pod.Name, err)
+	}
+	backup.Status.RedisVersion = server["redis_version"]
+
+	key := backupObjectKey(backup)
+	upload, err := objectStorage.CreateUpload(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to start upload of RDB snapshot: %w", err)
+	}
+	// Parts of an unfinished upload are kept by the storage until aborted
+	abort := func() {
+		if err := upload.Abort(context.WithoutCancel(ctx)); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to abort upload of RDB snapshot", "key", key)
+		}
+	}
+
+	hash := sha256.New()
+	size, err := redisClient.SyncRDB(ctx, io.MultiWriter(upload, hash))
+	if err != nil {
+		abort()
+		return fmt.Errorf("failed to take RDB snapshot of %s: %w", pod.Name, err)
+	}
+	if err := upload.Complete(); err != nil {
+		abort()
+		return fmt.Errorf("failed to upload RDB snapshot: %w", err)
+	}
+
+	backup.Status.Location = objectStorage.Location(key)
+	backup.Status.Size = size
+	backup.Status.Checksum = "sha256:" + hex.EncodeToString(hash.Sum(nil))
+	return nil
+}
+
+// cancelOnDeletion returns context which is cancelled once the backup is
+// deleted, so a backup replaced by a newer one stops its transfer
+func (r *RedisBackupReconciler) cancelOnDeletion(ctx context.Context, backup *cachev1alpha1.RedisBackup) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		ticker := time.NewTicker(backupDeletionPollPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			current := &cachev1alpha1.RedisBackup{}
+			err := r.Get(ctx, client.ObjectKeyFromObject(backup), current)
+			if apierrors.IsNotFound(err) || (err == nil && (current.UID != backup.UID || !current.DeletionTimestamp.IsZero())) {
+				cancel()
+				return
+			}
+		}
+	}()
+	return ctx, cancel
+}
+
+// backupObjectKey returns key of RDB file of the backup, <prefix>/<namespace>/<redis>/<backup>.rdb
+func backupObjectKey(backup *cachev1alpha1.RedisBackup) string {
+	return path.Join(backup.Spec.Storage.S3.Prefix, backup.Namespace, backup.Spec.Redis, backup.Name+".rdb")
+}
+
+// backupStorage returns client of object storage with credentials read from
+// the secret in namespace
+func backupStorage(ctx context.Context, c client.Reader, namespace string, spec *cachev1alpha1.BackupStorageSpec) (*storage.S3, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Name: spec.S3.CredentialsSecret, Namespace: namespace}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get credentials secret %s: %w", spec.S3.CredentialsSecret, err)
+	}
+	for _, key := range []string{storage.AccessKeyIDKey, storage.SecretAccessKeyKey} {
+		if len(secret.Data[key]) == 0 {
+			return nil, fmt.Errorf("credentials secret %s has no %s", spec.S3.CredentialsSecret, key)
+		}
+	}
+	return storage.NewS3(storage.S3Options{
+		Endpoint:        spec.S3.Endpoint,
+		Region:          spec.S3.Region,
+		Bucket:          spec.S3.Bucket,
+		AccessKeyID:     string(secret.Data[storage.AccessKeyIDKey]),
+		SecretAccessKey: string(secret.Data[storage.SecretAccessKeyKey]),
+	})
+}
+
+// failBackup marks the backup as failed with message
+func (r *RedisBackupReconciler) failBackup(ctx context.Context, backup *cachev1alpha1.RedisBackup, message string) error {
+	now := metav1.Now()
+	backup.Status.Phase = cachev1alpha1.BackupPhaseFailed
+	backup.Status.Message = message
+	backup.Status.CompletionTime = &now
+	return r.Status().Update(ctx, backup)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RedisBackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&cachev1alpha1.RedisBackup{}).
+		Complete(r)
+}
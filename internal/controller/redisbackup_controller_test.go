@@ -0,0 +1,137 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+)
+
+var _ = Describe("RedisBackup Controller", func() {
+	Context("When reconciling a resource", func() {
+		const resourceName = "test-backup"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind RedisBackup")
+			resource := &cachev1alpha1.RedisBackup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: cachev1alpha1.RedisBackupSpec{
+					Redis: "missing-redis",
+					Storage: cachev1alpha1.BackupStorageSpec{
+						S3: cachev1alpha1.S3StorageSpec{
+							Endpoint:          "http://minio.minio:9000",
+							Bucket:            "backups",
+							CredentialsSecret: "backup-credentials",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &cachev1alpha1.RedisBackup{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+
+			By("Cleanup the specific resource instance RedisBackup")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should fail backup of missing Redis", func() {
+			controllerReconciler := &RedisBackupReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &cachev1alpha1.RedisBackup{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Phase).To(Equal(cachev1alpha1.BackupPhaseFailed))
+			Expect(resource.Status.Message).To(ContainSubstring("missing-redis"))
+			Expect(resource.Status.CompletionTime).NotTo(BeNil())
+		})
+
+		It("should record completion time once the transfer has ended", func() {
+			resource := &cachev1alpha1.RedisBackup{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			startTime := metav1.Now()
+			resource.Status.Phase = cachev1alpha1.BackupPhaseRunning
+			resource.Status.StartTime = &startTime
+			Expect(k8sClient.Status().Update(ctx, resource)).To(Succeed())
+
+			controllerReconciler := &RedisBackupReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			// Times are stored with second precision, the transfer outlasts a second
+			controllerReconciler.runBackup(ctx, resource.DeepCopy(), func(ctx context.Context, backup *cachev1alpha1.RedisBackup) error {
+				time.Sleep(1100 * time.Millisecond)
+				backup.Status.Size = 1
+				return nil
+			})
+
+			Eventually(func() cachev1alpha1.RedisBackupPhase {
+				Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+				return resource.Status.Phase
+			}, 5*time.Second, 100*time.Millisecond).Should(Equal(cachev1alpha1.BackupPhaseCompleted))
+			Expect(resource.Status.CompletionTime).NotTo(BeNil())
+			Expect(resource.Status.CompletionTime.Sub(resource.Status.StartTime.Time)).To(BeNumerically(">=", time.Second))
+		})
+
+		It("should fail backup interrupted by restart of the operator", func() {
+			resource := &cachev1alpha1.RedisBackup{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			resource.Status.Phase = cachev1alpha1.BackupPhaseRunning
+			Expect(k8sClient.Status().Update(ctx, resource)).To(Succeed())
+
+			controllerReconciler := &RedisBackupReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			Expect(resource.Status.Phase).To(Equal(cachev1alpha1.BackupPhaseFailed))
+			Expect(resource.Status.Message).To(ContainSubstring("interrupted"))
+		})
+	})
+})
@@ -0,0 +1,282 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	"github.com/avekrivoy/redis-operator/internal/cron"
+	"github.com/avekrivoy/redis-operator/internal/metadata"
+)
+
+// RedisBackupScheduleReconciler reconciles a RedisBackupSchedule object
+type RedisBackupScheduleReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=cache.assignment.yazio.com,resources=redisbackupschedules,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=cache.assignment.yazio.com,resources=redisbackupschedules/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=cache.assignment.yazio.com,resources=redisbackups,verbs=get;list;watch;create;delete
+
+// Reconcile creates RedisBackup at each time of the schedule and deletes
+// backups beyond retention. If several schedules passed since the last one,
+// e.g. while the operator was not running, only the latest is taken and the
+// others are reported as missed.
+func (r *RedisBackupScheduleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	schedule := &cachev1alpha1.RedisBackupSchedule{}
+	if err := r.Get(ctx, req.NamespacedName, schedule); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	previous := schedule.Status.DeepCopy()
+	now := time.Now()
+
+	backupList := &cachev1alpha1.RedisBackupList{}
+	if err := r.List(ctx, backupList,
+		client.InNamespace(schedule.Namespace),
+		client.MatchingLabels{metadata.BackupScheduleLabel: schedule.Name},
+	); err != nil {
+		return ctrl.Result{}, err
+	}
+	active, err := r.pruneBackups(ctx, schedule, backupList.Items, now)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	requeueAfter := time.Duration(0)
+	cronSchedule, err := cron.Parse(schedule.Spec.Schedule)
+	switch {
+	case err != nil:
+		setScheduleCondition(schedule, cachev1alpha1.ConditionReady, false, "InvalidSchedule", err.Error())
+	case schedule.Spec.Suspend:
+		setScheduleCondition(schedule, cachev1alpha1.ConditionReady, true, "Suspended", "Backups are not taken while the schedule is suspended")
+	default:
+		if active, err = r.runSchedule(ctx, schedule, cronSchedule, active, now); err != nil {
+			return ctrl.Result{}, err
+		}
+		next := cronSchedule.Next(now)
+		if next.IsZero() {
+			setScheduleCondition(schedule, cachev1alpha1.ConditionReady, false, "NeverScheduled", "Schedule does not activate in the next five years")
+		} else {
+			requeueAfter = next.Sub(now)
+			setScheduleCondition(schedule, cachev1alpha1.ConditionReady, true, "Scheduled",
+				fmt.Sprintf("Next backup at %s", next.UTC().Format(time.RFC3339)))
+		}
+	}
+	schedule.Status.Active = active
+
+	if !equality.Semantic.DeepEqual(previous, &schedule.Status) {
+		if err := r.Status().Update(ctx, schedule); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// runSchedule creates backup of the latest schedule which passed since the
+// last one, respecting concurrency policy. It returns names of active backups.
+func (r *RedisBackupScheduleReconciler) runSchedule(ctx context.Context, schedule *cachev1alpha1.RedisBackupSchedule, cronSchedule *cron.Schedule, active []string, now time.Time) ([]string, error) {
+	logger := log.FromContext(ctx)
+
+	since := schedule.CreationTimestamp.Time
+	if schedule.Status.LastScheduleTime != nil {
+		since = schedule.Status.LastScheduleTime.Time
+	}
+	var latest, lastMissed time.Time
+	missed := int64(0)
+	for scheduled := cronSchedule.Next(since); !scheduled.IsZero() && !scheduled.After(now); scheduled = cronSchedule.Next(scheduled) {
+		if !latest.IsZero() {
+			missed++
+			lastMissed = latest
+		}
+		latest = scheduled
+	}
+	if latest.IsZero() {
+		return active, nil
+	}
+	schedule.Status.LastScheduleTime = &metav1.Time{Time: latest}
+	if missed > 0 {
+		recordMissedSchedules(schedule, missed, lastMissed, "OperatorDowntime", fmt.Sprintf(
+			"%d schedules up to %s were missed while the operator was not running", missed, lastMissed.UTC().Format(time.RFC3339)))
+	}
+
+	if len(active) > 0 {
+		switch schedule.Spec.ConcurrencyPolicy {
+		case cachev1alpha1.BackupConcurrencyForbid, "":
+			recordMissedSchedules(schedule, 1, latest, "ConcurrencyForbidden", fmt.Sprintf(
+				"Backup scheduled at %s was skipped, %s is still running", latest.UTC().Format(time.RFC3339), active[0]))
+			return active, nil
+		case cachev1alpha1.BackupConcurrencyReplace:
+			for _, name := range active {
+				backup := &cachev1alpha1.RedisBackup{}
+				backup.SetName(name)
+				backup.SetNamespace(schedule.Namespace)
+				if err := r.Delete(ctx, backup); client.IgnoreNotFound(err) != nil {
+					return nil, err
+				}
+				logger.Info("Replaced running backup", "backup", name)
+			}
+			active = nil
+		}
+	}
+
+	backup, err := r.createBackup(ctx, schedule, latest)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Scheduled backup created", "backup", backup.Name, "scheduledAt", latest)
+	schedule.Status.LastBackup = backup.Name
+	if missed == 0 {
+		setScheduleCondition(schedule, cachev1alpha1.ConditionSchedulesMissed, false, "OnSchedule", "Last backup was taken on schedule")
+	}
+	return append(active, backup.Name), nil
+}
+
+// createBackup creates RedisBackup owned by the schedule. Its name is derived
+// from the scheduled time, so a schedule is taken once.
+func (r *RedisBackupScheduleReconciler) createBackup(ctx context.Context, schedule *cachev1alpha1.RedisBackupSchedule, scheduled time.Time) (*cachev1alpha1.RedisBackup, error) {
+	backup := &cachev1alpha1.RedisBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%d", schedule.Name, scheduled.Unix()/60),
+			Namespace: schedule.Namespace,
+			Labels: metadata.ResourceLabels(schedule.Spec.Redis, metadata.Label{
+				metadata.BackupScheduleLabel: schedule.Name,
+			}),
+			Annotations: map[string]string{
+				metadata.ScheduledTimeAnnotation: scheduled.UTC().Format(time.RFC3339),
+			},
+		},
+		Spec: cachev1alpha1.RedisBackupSpec{
+			Redis:   schedule.Spec.Redis,
+			Storage: schedule.Spec.Storage,
+		},
+	}
+	if err := controllerutil.SetControllerReference(schedule, backup, r.Scheme); err != nil {
+		return nil, err
+	}
+	if err := r.Create(ctx, backup); err != nil && !apierrors.IsAlreadyExists(err) {
+		return nil, err
+	}
+	return backup, nil
+}
+
+// pruneBackups deletes completed backups beyond retention together with their
+// RDB files, and failed backups older than the newest completed backup. It
+// returns names of backups which are not finished.
+func (r *RedisBackupScheduleReconciler) pruneBackups(ctx context.Context, schedule *cachev1alpha1.RedisBackupSchedule, backups []cachev1alpha1.RedisBackup, now time.Time) ([]string, error) {
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].CreationTimestamp.Equal(&backups[j].CreationTimestamp) {
+			return backups[j].CreationTimestamp.Before(&backups[i].CreationTimestamp)
+		}
+		return backups[i].Name > backups[j].Name
+	})
+
+	retention := schedule.Spec.Retention
+	active := []string{}
+	var newestCompleted *cachev1alpha1.RedisBackup
+	kept := int32(0)
+	for i := range backups {
+		backup := &backups[i]
+		switch backup.Status.Phase {
+		case cachev1alpha1.BackupPhaseCompleted:
+			if newestCompleted == nil {
+				newestCompleted = backup
+				if schedule.Status.LastSuccessfulTime == nil || schedule.Status.LastSuccessfulTime.Before(backup.Status.CompletionTime) {
+					schedule.Status.LastSuccessfulTime = backup.Status.CompletionTime
+				}
+			} else if (retention.Count != nil && kept >= *retention.Count) ||
+				(retention.MaxAge != nil && now.Sub(backup.CreationTimestamp.Time) > retention.MaxAge.Duration) {
+				if err := r.deleteBackup(ctx, backup); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			kept++
+		case cachev1alpha1.BackupPhaseFailed:
+			if newestCompleted != nil {
+				if err := r.deleteBackup(ctx, backup); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			active = append(active, backup.Name)
+		}
+	}
+	sort.Strings(active)
+	return active, nil
+}
+
+// deleteBackup removes RDB file of the backup from the bucket and deletes the backup
+func (r *RedisBackupScheduleReconciler) deleteBackup(ctx context.Context, backup *cachev1alpha1.RedisBackup) error {
+	if backup.Status.Location != "" {
+		objectStorage, err := backupStorage(ctx, r.Client, backup.Namespace, &backup.Spec.Storage)
+		if err != nil {
+			return err
+		}
+		if err := objectStorage.DeleteObject(ctx, backupObjectKey(backup)); err != nil {
+			return fmt.Errorf("failed to delete RDB file of %s: %w", backup.Name, err)
+		}
+	}
+	if err := r.Delete(ctx, backup); client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	log.FromContext(ctx).Info("Pruned backup", "backup", backup.Name, "location", backup.Status.Location)
+	return nil
+}
+
+// recordMissedSchedules counts schedules no backup was taken for
+func recordMissedSchedules(schedule *cachev1alpha1.RedisBackupSchedule, missed int64, lastMissed time.Time, reason string, message string) {
+	schedule.Status.MissedSchedules += missed
+	schedule.Status.LastMissedTime = &metav1.Time{Time: lastMissed}
+	setScheduleCondition(schedule, cachev1alpha1.ConditionSchedulesMissed, true, reason, message)
+}
+
+func setScheduleCondition(schedule *cachev1alpha1.RedisBackupSchedule, conditionType string, status bool, reason string, message string) {
+	conditionStatus := metav1.ConditionFalse
+	if status {
+		conditionStatus = metav1.ConditionTrue
+	}
+	meta.SetStatusCondition(&schedule.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: schedule.Generation,
+	})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RedisBackupScheduleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&cachev1alpha1.RedisBackupSchedule{}).
+		Owns(&cachev1alpha1.RedisBackup{}).
+		Complete(r)
+}
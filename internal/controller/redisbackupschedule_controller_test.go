@@ -0,0 +1,92 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+)
+
+var _ = Describe("RedisBackupSchedule Controller", func() {
+	Context("When reconciling a resource", func() {
+		const resourceName = "test-backup-schedule"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      resourceName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind RedisBackupSchedule")
+			resource := &cachev1alpha1.RedisBackupSchedule{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourceName,
+					Namespace: "default",
+				},
+				Spec: cachev1alpha1.RedisBackupScheduleSpec{
+					Redis:    "redis",
+					Schedule: "0 25 * * *",
+					Storage: cachev1alpha1.BackupStorageSpec{
+						S3: cachev1alpha1.S3StorageSpec{
+							Endpoint:          "http://minio.minio:9000",
+							Bucket:            "backups",
+							CredentialsSecret: "backup-credentials",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &cachev1alpha1.RedisBackupSchedule{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+
+			By("Cleanup the specific resource instance RedisBackupSchedule")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should report invalid schedule", func() {
+			controllerReconciler := &RedisBackupScheduleReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			resource := &cachev1alpha1.RedisBackupSchedule{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, resource)).To(Succeed())
+			condition := meta.FindStatusCondition(resource.Status.Conditions, cachev1alpha1.ConditionReady)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Reason).To(Equal("InvalidSchedule"))
+			Expect(resource.Status.LastBackup).To(BeEmpty())
+		})
+	})
+})
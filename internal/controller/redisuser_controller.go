@@ -0,0 +1,227 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	"github.com/avekrivoy/redis-operator/internal/metadata"
+	redisclient "github.com/avekrivoy/redis-operator/internal/redis"
+	"github.com/avekrivoy/redis-operator/internal/resources"
+)
+
+// userRequeuePeriod is used while some Redis pods did not get the user yet
+const userRequeuePeriod = 10 * time.Second
+
+// RedisUserReconciler reconciles a RedisUser object
+type RedisUserReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=cache.assignment.yazio.com,resources=redisusers,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=cache.assignment.yazio.com,resources=redisusers/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=cache.assignment.yazio.com,resources=redisusers/finalizers,verbs=update
+
+// Reconcile generates password secret of RedisUser and applies the user with
+// ACL SETUSER to every running Redis pod. Pods started later load the user
+// from the ACL file rendered by Redis controller.
+func (r *RedisUserReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	user := &cachev1alpha1.RedisUser{}
+	if err := r.Get(ctx, req.NamespacedName, user); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	redis := &cachev1alpha1.Redis{}
+	err := r.Get(ctx, types.NamespacedName{Name: user.Spec.Redis, Namespace: user.Namespace}, redis)
+	if client.IgnoreNotFound(err) != nil {
+		return ctrl.Result{}, err
+	}
+	redisFound := err == nil
+
+	if !user.DeletionTimestamp.IsZero() {
+		if !controllerutil.ContainsFinalizer(user, cachev1alpha1.RedisUserFinalizer) {
+			return ctrl.Result{}, nil
+		}
+		if redisFound && redis.DeletionTimestamp.IsZero() {
+			if _, err := r.applyUser(ctx, redis, func(redisClient *redisclient.Client) error {
+				return redisClient.ACLDelUser(ctx, user.ACLUsername())
+			}); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		logger.Info("Redis user removed", "username", user.ACLUsername())
+		controllerutil.RemoveFinalizer(user, cachev1alpha1.RedisUserFinalizer)
+		return ctrl.Result{}, r.Update(ctx, user)
+	}
+
+	if controllerutil.AddFinalizer(user, cachev1alpha1.RedisUserFinalizer) {
+		if err := r.Update(ctx, user); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if !redisFound {
+		return ctrl.Result{}, r.updateUserStatus(ctx, user, false, "RedisNotFound",
+			fmt.Sprintf("Redis %s does not exist", user.Spec.Redis))
+	}
+
+	password, err := r.reconcileUserSecret(ctx, user)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	user.Status.SecretName = metadata.RedisUserSecretName(user.Name)
+
+	rules := append([]string{"reset"}, resources.UserACLRules(&user.Spec, password)...)
+	applied, err := r.applyUser(ctx, redis, func(redisClient *redisclient.Client) error {
+		return redisClient.ACLSetUser(ctx, user.ACLUsername(), rules...)
+	})
+	if err != nil {
+		if statusErr := r.updateUserStatus(ctx, user, false, "ApplyFailed", err.Error()); statusErr != nil {
+			logger.Error(statusErr, "Failed to update status")
+		}
+		return ctrl.Result{}, err
+	}
+	if !applied {
+		return ctrl.Result{RequeueAfter: userRequeuePeriod}, r.updateUserStatus(ctx, user, false, "PodsNotReady",
+			"User is not applied to Redis pods which are not ready yet")
+	}
+	return ctrl.Result{}, r.updateUserStatus(ctx, user, true, "Applied", "User is applied to all Redis pods")
+}
+
+// reconcileUserSecret creates secret with credentials of the user and returns
+// its password. Password is generated only once.
+func (r *RedisUserReconciler) reconcileUserSecret(ctx context.Context, user *cachev1alpha1.RedisUser) (string, error) {
+	secret := &corev1.Secret{}
+	secret.SetName(metadata.RedisUserSecretName(user.Name))
+	secret.SetNamespace(user.Namespace)
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		secret.Labels = metadata.ResourceLabels(user.Spec.Redis, metadata.Label{
+			"app.kubernetes.io/component": metadata.DefaultComponent,
+		})
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		secret.Data["REDIS_USERNAME"] = []byte(user.ACLUsername())
+		if len(secret.Data[resources.AuthPasswordKey]) == 0 {
+			password, err := resources.GeneratePassword()
+			if err != nil {
+				return err
+			}
+			secret.Data[resources.AuthPasswordKey] = []byte(password)
+		}
+		return controllerutil.SetControllerReference(user, secret, r.Scheme)
+	})
+	return string(secret.Data[resources.AuthPasswordKey]), err
+}
+
+// applyUser runs update on every ready Redis pod authenticated as the default
+// user. It returns false if some pod is not ready.
+func (r *RedisUserReconciler) applyUser(ctx context.Context, redis *cachev1alpha1.Redis, update func(*redisclient.Client) error) (bool, error) {
+	options, err := connectionOptions(ctx, r.Client, redis)
+	if err != nil {
+		return false, err
+	}
+
+	applied := true
+	for _, component := range []string{metadata.RedisMasterComponent(), metadata.RedisReplicaComponent(), metadata.RedisClusterComponent()} {
+		pods, err := listPods(ctx, r.Client, redis, metadata.LabelSelector(redis.Name, component))
+		if err != nil {
+			return false, err
+		}
+		for _, pod := range pods {
+			if !isPodReady(&pod) || pod.Status.PodIP == "" {
+				applied = false
+				continue
+			}
+			redisClient, err := redisclient.Dial(ctx, podAddress(&pod), options)
+			if err != nil {
+				return false, fmt.Errorf("failed to connect to %s: %w", pod.Name, err)
+			}
+			err = update(redisClient)
+			redisClient.Close()
+			if err != nil {
+				return false, fmt.Errorf("failed to update ACL of %s: %w", pod.Name, err)
+			}
+		}
+	}
+	return applied, nil
+}
+
+func (r *RedisUserReconciler) updateUserStatus(ctx context.Context, user *cachev1alpha1.RedisUser, ready bool, reason string, message string) error {
+	status := metav1.ConditionFalse
+	if ready {
+		status = metav1.ConditionTrue
+	}
+	meta.SetStatusCondition(&user.Status.Conditions, metav1.Condition{
+		Type:               cachev1alpha1.ConditionReady,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: user.Generation,
+	})
+	user.Status.ObservedGeneration = user.Generation
+	return r.Status().Update(ctx, user)
+}
+
+// usersOfRedis enqueues users of Redis instance, so they are applied once
+// Redis is created or its pods are replaced
+func (r *RedisUserReconciler) usersOfRedis(ctx context.Context, object client.Object) []reconcile.Request {
+	redis, ok := object.(*cachev1alpha1.Redis)
+	if !ok {
+		return nil
+	}
+	users, err := redisUsers(ctx, r.Client, redis)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list Redis users")
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, user := range users {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+			Name:      user.Name,
+			Namespace: user.Namespace,
+		}})
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RedisUserReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&cachev1alpha1.RedisUser{}).
+		Owns(&corev1.Secret{}).
+		Watches(&cachev1alpha1.Redis{}, handler.EnqueueRequestsFromMapFunc(r.usersOfRedis)).
+		Complete(r)
+}
@@ -0,0 +1,173 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// searchLimit bounds search of the next activation of schedules which never
+// activate, e.g. '0 0 30 2 *'
+const searchLimit = 5 * 366 * 24 * time.Hour
+
+// macros are shorthands of common schedules
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// bounds are allowed values of a schedule field
+type bounds struct {
+	name  string
+	min   int
+	max   int
+	names map[string]int
+}
+
+var (
+	minuteBounds = bounds{name: "minute", min: 0, max: 59}
+	hourBounds   = bounds{name: "hour", min: 0, max: 23}
+	domBounds    = bounds{name: "day of month", min: 1, max: 31}
+	monthBounds  = bounds{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Both 0 and 7 are Sunday
+	dowBounds = bounds{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// Schedule is a parsed standard cron expression with minute, hour, day of
+// month, month and day of week fields
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// Day matches either day field if both are restricted, as in cron
+	domRestricted, dowRestricted bool
+}
+
+// Parse parses cron expression, e.g. '0 3 * * *', '*/15 * * * 1-5' or '@daily'
+func Parse(expression string) (*Schedule, error) {
+	expression = strings.TrimSpace(expression)
+	if macro, ok := macros[strings.ToLower(expression)]; ok {
+		expression = macro
+	}
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields in cron expression %q, got %d", expression, len(fields))
+	}
+
+	schedule := &Schedule{
+		domRestricted: fields[2] != "*",
+		dowRestricted: fields[4] != "*",
+	}
+	var err error
+	for i, field := range []struct {
+		bits   *uint64
+		bounds bounds
+	}{
+		{&schedule.minute, minuteBounds},
+		{&schedule.hour, hourBounds},
+		{&schedule.dom, domBounds},
+		{&schedule.month, monthBounds},
+		{&schedule.dow, dowBounds},
+	} {
+		if *field.bits, err = parseField(fields[i], field.bounds); err != nil {
+			return nil, err
+		}
+	}
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+	}
+	return schedule, nil
+}
+
+// parseField returns bit set of values of comma separated list of values,
+// ranges and steps, e.g. '1,15', '9-17' or '*/5'
+func parseField(field string, b bounds) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepExpr); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q of %s", stepExpr, b.name)
+			}
+		}
+
+		first, last := b.min, b.max
+		if rangeExpr != "*" {
+			start, end, isRange := strings.Cut(rangeExpr, "-")
+			var err error
+			if first, err = parseValue(start, b); err != nil {
+				return 0, err
+			}
+			last = first
+			if isRange {
+				if last, err = parseValue(end, b); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				// 'a/n' means every n-th value starting from a
+				last = b.max
+			}
+			if first > last {
+				return 0, fmt.Errorf("invalid range %q of %s", rangeExpr, b.name)
+			}
+		}
+		for value := first; value <= last; value += step {
+			bits |= 1 << value
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(value string, b bounds) (int, error) {
+	if number, ok := b.names[strings.ToLower(value)]; ok {
+		return number, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < b.min || number > b.max {
+		return 0, fmt.Errorf("invalid %s %q, expected %d-%d", b.name, value, b.min, b.max)
+	}
+	return number, nil
+}
+
+// Next returns the first activation of the schedule after t in location of t.
+// It is zero if the schedule does not activate in the next five years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	location := t.Location()
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, location).Add(time.Minute)
+	limit := t.Add(searchLimit)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, location)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, location)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, location)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatches := s.dom&(1<<uint(t.Day())) != 0
+	dowMatches := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domRestricted && s.dowRestricted {
+		return domMatches || dowMatches
+	}
+	return domMatches && dowMatches
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cron
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cron schedule", func() {
+	// Monday
+	start := time.Date(2024, 5, 6, 10, 30, 15, 0, time.UTC)
+
+	DescribeTable("should find the next activation",
+		func(expression string, expected time.Time) {
+			schedule, err := Parse(expression)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(schedule.Next(start)).To(Equal(expected))
+		},
+		Entry("every minute", "* * * * *", time.Date(2024, 5, 6, 10, 31, 0, 0, time.UTC)),
+		Entry("step", "*/20 * * * *", time.Date(2024, 5, 6, 10, 40, 0, 0, time.UTC)),
+		Entry("daily", "@daily", time.Date(2024, 5, 7, 0, 0, 0, 0, time.UTC)),
+		Entry("hour list", "15 3,12 * * *", time.Date(2024, 5, 6, 12, 15, 0, 0, time.UTC)),
+		Entry("weekday names", "0 9 * * sat,sun", time.Date(2024, 5, 11, 9, 0, 0, 0, time.UTC)),
+		Entry("sunday as 7", "0 9 * * 7", time.Date(2024, 5, 12, 9, 0, 0, 0, time.UTC)),
+		Entry("next year", "0 0 1 jan *", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+		Entry("day of month or week", "0 0 20 * mon", time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)),
+		Entry("leap day", "0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)),
+	)
+
+	It("should not activate on impossible date", func() {
+		schedule, err := Parse("0 0 30 2 *")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(schedule.Next(start).IsZero()).To(BeTrue())
+	})
+
+	DescribeTable("should reject invalid expression",
+		func(expression string) {
+			_, err := Parse(expression)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("too few fields", "* * * *"),
+		Entry("out of range", "60 * * * *"),
+		Entry("reversed range", "0 10-2 * * *"),
+		Entry("reversed weekday range", "0 9 * * sat-sun"),
+		Entry("zero step", "*/0 * * * *"),
+		Entry("unknown name", "0 0 * * funday"),
+	)
+})
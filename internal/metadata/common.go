@@ -6,6 +6,10 @@ import (
 
 const (
 	AuthSecretSuffix = "auth-secret"
+	ACLSecretSuffix  = "acl"
+	ConfigMapSuffix  = "redis-config"
+	TLSSecretSuffix  = "tls"
+	UserSecretSuffix = "redis-user"
 	DefaultComponent = "redis"
 )
 
@@ -14,6 +18,26 @@ func RedisAuthSecretName(name string) string {
 	return secretName
 }
 
+// RedisACLSecretName returns name of the secret with ACL file loaded by Redis pods
+func RedisACLSecretName(name string) string {
+	return fmt.Sprintf("%s-%s", name, ACLSecretSuffix)
+}
+
+// RedisConfigMapName returns name of the ConfigMap with redis.conf of Redis pods
+func RedisConfigMapName(name string) string {
+	return fmt.Sprintf("%s-%s", name, ConfigMapSuffix)
+}
+
+// RedisTLSSecretName returns name of the secret with generated TLS certificates
+func RedisTLSSecretName(name string) string {
+	return fmt.Sprintf("%s-%s", name, TLSSecretSuffix)
+}
+
+// RedisUserSecretName returns name of the secret with credentials of RedisUser
+func RedisUserSecretName(userName string) string {
+	return fmt.Sprintf("%s-%s", userName, UserSecretSuffix)
+}
+
 func RedisMasterComponent() string {
 	return "redis-master"
 }
@@ -22,6 +46,19 @@ func RedisReplicaComponent() string {
 	return "redis-replica"
 }
 
+func RedisSentinelComponent() string {
+	return "redis-sentinel"
+}
+
+func RedisClusterComponent() string {
+	return "redis-cluster"
+}
+
+// RedisClusterShardName returns name of the StatefulSet running cluster shard
+func RedisClusterShardName(name string, shard int32) string {
+	return fmt.Sprintf("%s-%s-%d", name, RedisClusterComponent(), shard)
+}
+
 func RedisServiceName(name string, component string) string {
 	return fmt.Sprintf("%s-%s", name, component)
 }
@@ -1,5 +1,7 @@
 package metadata
 
+import "strconv"
+
 type Label map[string]string
 
 func CommonLabels(instanceName string) Label {
@@ -23,3 +25,58 @@ func LabelSelector(instanceName string, resource string) Label {
 		"app.kubernetes.io/component": resource,
 	}
 }
+
+// WorkloadKindLabel distinguishes pods of Deployment and StatefulSet of the same component
+const WorkloadKindLabel = "cache.assignment.yazio.com/workload-kind"
+
+func WorkloadLabelSelector(instanceName string, resource string, kind string) Label {
+	selector := LabelSelector(instanceName, resource)
+	selector[WorkloadKindLabel] = kind
+	return selector
+}
+
+// RoleLabel marks Redis pods with their current replication role in Sentinel mode
+const RoleLabel = "cache.assignment.yazio.com/role"
+
+const (
+	RoleMaster  = "master"
+	RoleReplica = "replica"
+)
+
+func RoleLabelSelector(instanceName string, role string) Label {
+	return Label{
+		"app.kubernetes.io/name": instanceName,
+		RoleLabel:                role,
+	}
+}
+
+// ShardLabel holds index of Redis Cluster shard the pod belongs to
+const ShardLabel = "cache.assignment.yazio.com/shard"
+
+func ShardLabelSelector(instanceName string, shard int32) Label {
+	selector := LabelSelector(instanceName, RedisClusterComponent())
+	selector[ShardLabel] = strconv.Itoa(int(shard))
+	return selector
+}
+
+// PasswordRotationAnnotation of replica pods is changed by password rotation
+// to restart them with the new master password
+const PasswordRotationAnnotation = "cache.assignment.yazio.com/password-rotation"
+
+// TLSSerialAnnotation of Sentinel pods holds serial number of the serving
+// certificate, Sentinel is restarted to load renewed certificate
+const TLSSerialAnnotation = "cache.assignment.yazio.com/tls-serial"
+
+// ConfigChecksumAnnotation of Redis pods holds checksum of redis.conf
+// directives requiring restart, pods are restarted when they change
+const ConfigChecksumAnnotation = "cache.assignment.yazio.com/config-checksum"
+
+// AppliedConfigAnnotation of Redis pods holds redis.conf directives in effect
+// in the pod, runtime directives are updated by the operator with CONFIG SET
+const AppliedConfigAnnotation = "cache.assignment.yazio.com/applied-config"
+
+// BackupScheduleLabel of RedisBackup holds name of RedisBackupSchedule which created it
+const BackupScheduleLabel = "cache.assignment.yazio.com/backup-schedule"
+
+// ScheduledTimeAnnotation of RedisBackup holds the time it was scheduled at
+const ScheduledTimeAnnotation = "cache.assignment.yazio.com/scheduled-at"
@@ -0,0 +1,30 @@
+package redis
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// DefaultUser is the user clients authenticate as with password only
+const DefaultUser = "default"
+
+// ACLSetUser creates user or modifies its ACL rules, e.g. ">password" adds a password
+func (c *Client) ACLSetUser(ctx context.Context, user string, rules ...string) error {
+	args := append([]string{"ACL", "SETUSER", user}, rules...)
+	_, err := c.Do(ctx, args...)
+	return err
+}
+
+// ACLDelUser removes user and terminates its connections
+func (c *Client) ACLDelUser(ctx context.Context, user string) error {
+	_, err := c.Do(ctx, "ACL", "DELUSER", user)
+	return err
+}
+
+// PasswordHash returns SHA-256 hash of the password used by "#<hash>" ACL rule,
+// so ACL files do not contain passwords in plain text
+func PasswordHash(password string) string {
+	hash := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,231 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultTimeout = 5 * time.Second
+
+// Error is an error reply returned by Redis server
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+// Client is a minimal RESP client used by the operator to manage Redis pods.
+// It is not safe for concurrent use.
+type Client struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// Options configure connection to Redis server
+type Options struct {
+	// Password to authenticate with, no authentication if empty
+	Password string
+	// TLS configuration, plain TCP is used if nil
+	TLS *tls.Config
+}
+
+// Dial connects to Redis server at addr
+func Dial(ctx context.Context, addr string, options Options) (*Client, error) {
+	dialer := net.Dialer{Timeout: defaultTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.TLS != nil {
+		tlsConn := tls.Client(conn, options.TLS)
+		handshakeCtx, cancel := context.WithTimeout(ctx, defaultTimeout)
+		defer cancel()
+		if err := tlsConn.HandshakeContext(handshakeCtx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("TLS handshake with %s failed: %w", addr, err)
+		}
+		conn = tlsConn
+	}
+
+	c := &Client{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+	}
+
+	if options.Password != "" {
+		if _, err := c.Do(ctx, "AUTH", options.Password); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("failed to authenticate to %s: %w", addr, err)
+		}
+	}
+	return c, nil
+}
+
+// PeerCertificate returns certificate presented by the server, nil without TLS
+func (c *Client) PeerCertificate() *x509.Certificate {
+	tlsConn, ok := c.conn.(*tls.Conn)
+	if !ok {
+		return nil
+	}
+	certificates := tlsConn.ConnectionState().PeerCertificates
+	if len(certificates) == 0 {
+		return nil
+	}
+	return certificates[0]
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Do sends command to Redis and returns its reply. Replies are converted to
+// string, int64, []interface{} or nil. Error replies are returned as Error.
+func (c *Client) Do(ctx context.Context, args ...string) (interface{}, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultTimeout)
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	if err := c.writeCommand(args...); err != nil {
+		return nil, err
+	}
+	return readReply(c.reader)
+}
+
+// writeCommand sends command as RESP array of bulk strings
+func (c *Client) writeCommand(args ...string) error {
+	var cmd strings.Builder
+	fmt.Fprintf(&cmd, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&cmd, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	_, err := io.WriteString(c.conn, cmd.String())
+	return err
+}
+
+// String sends command and expects simple or bulk string reply
+func (c *Client) String(ctx context.Context, args ...string) (string, error) {
+	reply, err := c.Do(ctx, args...)
+	if err != nil {
+		return "", err
+	}
+	s, ok := reply.(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected reply type %T to %s", reply, args[0])
+	}
+	return s, nil
+}
+
+// Info returns fields of INFO section as key-value map
+func (c *Client) Info(ctx context.Context, section string) (map[string]string, error) {
+	reply, err := c.String(ctx, "INFO", section)
+	if err != nil {
+		return nil, err
+	}
+	return ParseInfo(reply), nil
+}
+
+// ReplicaOf makes server replicate from host:port
+func (c *Client) ReplicaOf(ctx context.Context, host string, port int) error {
+	_, err := c.Do(ctx, "REPLICAOF", host, strconv.Itoa(port))
+	return err
+}
+
+// ReplicaOfNoOne promotes replica to master
+func (c *Client) ReplicaOfNoOne(ctx context.Context) error {
+	_, err := c.Do(ctx, "REPLICAOF", "NO", "ONE")
+	return err
+}
+
+// ConfigSet changes server configuration at runtime
+func (c *Client) ConfigSet(ctx context.Context, key string, value string) error {
+	_, err := c.Do(ctx, "CONFIG", "SET", key, value)
+	return err
+}
+
+// ParseInfo parses INFO reply into key-value map skipping section headers
+func ParseInfo(info string) map[string]string {
+	fields := map[string]string{}
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, found := strings.Cut(line, ":")
+		if found {
+			fields[key] = value
+		}
+	}
+	return fields
+}
+
+func readReply(reader *bufio.Reader) (interface{}, error) {
+	line, err := readLine(reader)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, Error(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		return string(data[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, 0, count)
+		for i := 0; i < count; i++ {
+			item, err := readReply(reader)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("unexpected reply %q", line)
+}
+
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(line, "\r\n"), nil
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Redis client", func() {
+	var server *fakeServer
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		server = newFakeServer("secret")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should authenticate with password", func() {
+		_, err := Dial(ctx, server.Addr(), Options{Password: "wrong"})
+		Expect(err).To(MatchError(ContainSubstring("WRONGPASS")))
+
+		client, err := Dial(ctx, server.Addr(), Options{Password: "secret"})
+		Expect(err).NotTo(HaveOccurred())
+		defer client.Close()
+		Expect(client.Ping(ctx)).To(Succeed())
+		Expect(server.Commands()).To(ContainElement([]string{"AUTH", "secret"}))
+	})
+
+	It("should return error replies as Error", func() {
+		client, err := Dial(ctx, server.Addr(), Options{Password: "secret"})
+		Expect(err).NotTo(HaveOccurred())
+		defer client.Close()
+
+		_, err = client.Do(ctx, "FOO")
+		var redisErr Error
+		Expect(err).To(BeAssignableToTypeOf(redisErr))
+	})
+
+	It("should add password of the default user", func() {
+		server.Reply("ACL", "+OK\r\n")
+		client, err := Dial(ctx, server.Addr(), Options{Password: "secret"})
+		Expect(err).NotTo(HaveOccurred())
+		defer client.Close()
+
+		Expect(client.ACLSetUser(ctx, DefaultUser, "on", ">rotated")).To(Succeed())
+		Expect(server.Commands()).To(ContainElement([]string{"ACL", "SETUSER", "default", "on", ">rotated"}))
+	})
+
+	It("should parse replication info of a replica", func() {
+		server.Reply("INFO", bulkString("# Replication\r\nrole:slave\r\nmaster_host:10.0.0.1\r\nmaster_port:6379\r\n"+
+			"master_link_status:up\r\nmaster_sync_in_progress:0\r\nslave_repl_offset:1234\r\nmaster_repl_offset:1234\r\nconnected_slaves:0\r\n"))
+
+		client, err := Dial(ctx, server.Addr(), Options{Password: "secret"})
+		Expect(err).NotTo(HaveOccurred())
+		defer client.Close()
+
+		info, err := client.Replication(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.IsMaster()).To(BeFalse())
+		Expect(info.InSync()).To(BeTrue())
+		Expect(info.MasterHost).To(Equal("10.0.0.1"))
+		Expect(info.MasterPort).To(Equal(6379))
+		Expect(info.SlaveReplOffset).To(Equal(int64(1234)))
+	})
+
+	It("should parse ROLE reply of a master", func() {
+		server.Reply("ROLE", "*3\r\n"+bulkString("master")+":3129659\r\n*2\r\n"+
+			"*3\r\n"+bulkString("10.0.0.2")+bulkString("6379")+bulkString("3129242")+
+			"*3\r\n"+bulkString("10.0.0.3")+bulkString("6379")+bulkString("3129543"))
+
+		client, err := Dial(ctx, server.Addr(), Options{Password: "secret"})
+		Expect(err).NotTo(HaveOccurred())
+		defer client.Close()
+
+		role, err := client.Role(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(role.IsMaster()).To(BeTrue())
+		Expect(role.Offset).To(Equal(int64(3129659)))
+		Expect(role.Replicas).To(Equal([]ReplicaEntry{
+			{IP: "10.0.0.2", Port: 6379, Offset: 3129242},
+			{IP: "10.0.0.3", Port: 6379, Offset: 3129543},
+		}))
+	})
+
+	It("should parse ROLE reply of a replica", func() {
+		server.Reply("ROLE", "*5\r\n"+bulkString("slave")+bulkString("10.0.0.1")+":6379\r\n"+bulkString("connected")+":3167038\r\n")
+
+		client, err := Dial(ctx, server.Addr(), Options{Password: "secret"})
+		Expect(err).NotTo(HaveOccurred())
+		defer client.Close()
+
+		role, err := client.Role(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(role).To(Equal(&RoleInfo{
+			Role:       RoleReplica,
+			Offset:     3167038,
+			MasterHost: "10.0.0.1",
+			MasterPort: 6379,
+			State:      "connected",
+		}))
+	})
+})
@@ -0,0 +1,344 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ClusterSlots is the number of hash slots in Redis Cluster
+const ClusterSlots = 16384
+
+// SlotRange is an inclusive range of hash slots
+type SlotRange struct {
+	Start int
+	End   int
+}
+
+func (r SlotRange) String() string {
+	if r.Start == r.End {
+		return strconv.Itoa(r.Start)
+	}
+	return fmt.Sprintf("%d-%d", r.Start, r.End)
+}
+
+// Size returns number of slots in range
+func (r SlotRange) Size() int {
+	return r.End - r.Start + 1
+}
+
+// SplitSlots evenly splits all cluster slots into ranges for given number of shards
+func SplitSlots(shards int) []SlotRange {
+	ranges := make([]SlotRange, 0, shards)
+	start := 0
+	for i := 0; i < shards; i++ {
+		size := ClusterSlots / shards
+		if i < ClusterSlots%shards {
+			size++
+		}
+		ranges = append(ranges, SlotRange{Start: start, End: start + size - 1})
+		start += size
+	}
+	return ranges
+}
+
+// FormatSlots formats slot ranges as space separated list, e.g. "0-5460 10923"
+func FormatSlots(ranges []SlotRange) string {
+	parts := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		parts = append(parts, r.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+// UnassignedSlots returns parts of slot range not owned by any of the nodes
+func UnassignedSlots(within SlotRange, nodes []ClusterNode) []SlotRange {
+	owned := make([]bool, ClusterSlots)
+	for _, node := range nodes {
+		for _, r := range node.Slots {
+			for slot := r.Start; slot <= r.End && slot < ClusterSlots; slot++ {
+				owned[slot] = true
+			}
+		}
+	}
+
+	ranges := []SlotRange{}
+	for slot := within.Start; slot <= within.End; slot++ {
+		if owned[slot] {
+			continue
+		}
+		if n := len(ranges); n > 0 && ranges[n-1].End == slot-1 {
+			ranges[n-1].End = slot
+		} else {
+			ranges = append(ranges, SlotRange{Start: slot, End: slot})
+		}
+	}
+	return ranges
+}
+
+// SlotMove is a single slot moved between shards during resharding
+type SlotMove struct {
+	Slot int
+	From int
+	To   int
+}
+
+// slotSurplus returns difference between number of slots owned by every shard
+// and number of slots it owns when slots are split evenly between shards.
+// Shards with index out of shards range have to give away all slots.
+func slotSurplus(owned [][]SlotRange, shards int) []int {
+	target := SplitSlots(shards)
+	surplus := make([]int, len(owned))
+	for i, ranges := range owned {
+		for _, r := range ranges {
+			surplus[i] += r.Size()
+		}
+		if i < shards {
+			surplus[i] -= target[i].Size()
+		}
+	}
+	return surplus
+}
+
+// SlotsToMove returns number of slots to move to split slots evenly between shards
+func SlotsToMove(owned [][]SlotRange, shards int) int {
+	total := 0
+	for _, surplus := range slotSurplus(owned, shards) {
+		if surplus > 0 {
+			total += surplus
+		}
+	}
+	return total
+}
+
+// NextSlotMove returns move of the highest slot of the most overloaded shard to
+// the most underloaded one. It returns false when slots are already balanced.
+func NextSlotMove(owned [][]SlotRange, shards int) (SlotMove, bool) {
+	surplus := slotSurplus(owned, shards)
+	from, to := -1, -1
+	for i := range surplus {
+		if surplus[i] > 0 && (from < 0 || surplus[i] > surplus[from]) {
+			from = i
+		}
+		if surplus[i] < 0 && (to < 0 || surplus[i] < surplus[to]) {
+			to = i
+		}
+	}
+	if from < 0 || to < 0 {
+		return SlotMove{}, false
+	}
+
+	slot := -1
+	for _, r := range owned[from] {
+		if r.End > slot {
+			slot = r.End
+		}
+	}
+	return SlotMove{Slot: slot, From: from, To: to}, true
+}
+
+// Apply updates slot ownership after the move
+func (m SlotMove) Apply(owned [][]SlotRange) {
+	from := owned[m.From][:0]
+	for _, r := range owned[m.From] {
+		switch {
+		case r.Start == m.Slot && r.End == m.Slot:
+			continue
+		case r.End == m.Slot:
+			r.End--
+		case r.Start == m.Slot:
+			r.Start++
+		case r.Start < m.Slot && m.Slot < r.End:
+			from = append(from, SlotRange{Start: r.Start, End: m.Slot - 1})
+			r.Start = m.Slot + 1
+		}
+		from = append(from, r)
+	}
+	owned[m.From] = from
+
+	for i, r := range owned[m.To] {
+		if r.Start == m.Slot+1 {
+			owned[m.To][i].Start--
+			return
+		}
+		if r.End == m.Slot-1 {
+			owned[m.To][i].End++
+			return
+		}
+	}
+	owned[m.To] = append(owned[m.To], SlotRange{Start: m.Slot, End: m.Slot})
+}
+
+// ClusterNode is a node entry of CLUSTER NODES reply
+type ClusterNode struct {
+	ID string
+	// IP address of the node, port is omitted as all nodes listen on the same port
+	IP       string
+	Flags    []string
+	MasterID string
+	Slots    []SlotRange
+}
+
+func (n *ClusterNode) HasFlag(flag string) bool {
+	for _, f := range n.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *ClusterNode) IsMaster() bool {
+	return n.HasFlag("master")
+}
+
+// ParseClusterNodes parses CLUSTER NODES reply. Slots being imported or
+// migrated (e.g. "[42->-nodeid]") are skipped.
+func ParseClusterNodes(reply string) ([]ClusterNode, error) {
+	nodes := []ClusterNode{}
+	for _, line := range strings.Split(reply, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 8 {
+			return nil, fmt.Errorf("malformed CLUSTER NODES line %q", line)
+		}
+
+		// Address has form ip:port@cport[,hostname]
+		addr, _, _ := strings.Cut(fields[1], "@")
+		ip, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, fmt.Errorf("malformed node address %q: %w", fields[1], err)
+		}
+
+		node := ClusterNode{
+			ID:    fields[0],
+			IP:    ip,
+			Flags: strings.Split(fields[2], ","),
+		}
+		if fields[3] != "-" {
+			node.MasterID = fields[3]
+		}
+		for _, slot := range fields[8:] {
+			if strings.HasPrefix(slot, "[") {
+				continue
+			}
+			startStr, endStr, isRange := strings.Cut(slot, "-")
+			if !isRange {
+				endStr = startStr
+			}
+			start, err := strconv.Atoi(startStr)
+			if err != nil {
+				return nil, fmt.Errorf("malformed slot %q: %w", slot, err)
+			}
+			end, err := strconv.Atoi(endStr)
+			if err != nil {
+				return nil, fmt.Errorf("malformed slot %q: %w", slot, err)
+			}
+			node.Slots = append(node.Slots, SlotRange{Start: start, End: end})
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// ClusterNodes returns nodes known to the server
+func (c *Client) ClusterNodes(ctx context.Context) ([]ClusterNode, error) {
+	reply, err := c.String(ctx, "CLUSTER", "NODES")
+	if err != nil {
+		return nil, err
+	}
+	return ParseClusterNodes(reply)
+}
+
+// ClusterMyself returns node entry of the server itself
+func (c *Client) ClusterMyself(ctx context.Context) (*ClusterNode, error) {
+	nodes, err := c.ClusterNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range nodes {
+		if nodes[i].HasFlag("myself") {
+			return &nodes[i], nil
+		}
+	}
+	return nil, fmt.Errorf("CLUSTER NODES reply has no myself entry")
+}
+
+// ClusterInfo returns CLUSTER INFO fields
+func (c *Client) ClusterInfo(ctx context.Context) (map[string]string, error) {
+	reply, err := c.String(ctx, "CLUSTER", "INFO")
+	if err != nil {
+		return nil, err
+	}
+	return ParseInfo(reply), nil
+}
+
+// ClusterMeet connects the server to node at host:port
+func (c *Client) ClusterMeet(ctx context.Context, host string, port int) error {
+	_, err := c.Do(ctx, "CLUSTER", "MEET", host, strconv.Itoa(port))
+	return err
+}
+
+// ClusterAddSlots assigns slot range to the server
+func (c *Client) ClusterAddSlots(ctx context.Context, slots SlotRange) error {
+	_, err := c.Do(ctx, "CLUSTER", "ADDSLOTSRANGE", strconv.Itoa(slots.Start), strconv.Itoa(slots.End))
+	return err
+}
+
+// ClusterReplicate makes the server a replica of master node
+func (c *Client) ClusterReplicate(ctx context.Context, masterID string) error {
+	_, err := c.Do(ctx, "CLUSTER", "REPLICATE", masterID)
+	return err
+}
+
+// ClusterForget removes node from the node table of the server
+func (c *Client) ClusterForget(ctx context.Context, nodeID string) error {
+	_, err := c.Do(ctx, "CLUSTER", "FORGET", nodeID)
+	return err
+}
+
+// ClusterSetSlot changes state of slot on the server, state is one of
+// IMPORTING, MIGRATING or NODE followed by node ID, or STABLE without it
+func (c *Client) ClusterSetSlot(ctx context.Context, slot int, state string, nodeID string) error {
+	args := []string{"CLUSTER", "SETSLOT", strconv.Itoa(slot), state}
+	if nodeID != "" {
+		args = append(args, nodeID)
+	}
+	_, err := c.Do(ctx, args...)
+	return err
+}
+
+// ClusterGetKeysInSlot returns up to count keys stored in slot
+func (c *Client) ClusterGetKeysInSlot(ctx context.Context, slot int, count int) ([]string, error) {
+	reply, err := c.Do(ctx, "CLUSTER", "GETKEYSINSLOT", strconv.Itoa(slot), strconv.Itoa(count))
+	if err != nil {
+		return nil, err
+	}
+	items, ok := reply.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected reply %v to CLUSTER GETKEYSINSLOT", reply)
+	}
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		key, _ := item.(string)
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Migrate atomically moves keys to Redis at host:port replacing existing ones
+func (c *Client) Migrate(ctx context.Context, host string, port int, timeout time.Duration, password string, keys []string) error {
+	args := []string{"MIGRATE", host, strconv.Itoa(port), "", "0", strconv.Itoa(int(timeout.Milliseconds())), "REPLACE"}
+	if password != "" {
+		args = append(args, "AUTH", password)
+	}
+	args = append(args, "KEYS")
+	args = append(args, keys...)
+	_, err := c.Do(ctx, args...)
+	return err
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Redis Cluster helpers", func() {
+	It("should split slots evenly between shards", func() {
+		ranges := SplitSlots(3)
+		Expect(ranges).To(Equal([]SlotRange{
+			{Start: 0, End: 5461},
+			{Start: 5462, End: 10922},
+			{Start: 10923, End: 16383},
+		}))
+		Expect(FormatSlots(ranges[:1])).To(Equal("0-5461"))
+	})
+
+	It("should find slots not owned by any node", func() {
+		nodes := []ClusterNode{
+			{ID: "a", Slots: []SlotRange{{Start: 0, End: 5461}}},
+			{ID: "b", Slots: []SlotRange{{Start: 5470, End: 5480}, {Start: 10000, End: 10922}}},
+		}
+		Expect(UnassignedSlots(SplitSlots(3)[0], nodes)).To(BeEmpty())
+		Expect(UnassignedSlots(SplitSlots(3)[1], nodes)).To(Equal([]SlotRange{
+			{Start: 5462, End: 5469},
+			{Start: 5481, End: 9999},
+		}))
+		Expect(UnassignedSlots(SplitSlots(3)[2], nodes)).To(Equal([]SlotRange{{Start: 10923, End: 16383}}))
+	})
+
+	It("should parse CLUSTER NODES reply", func() {
+		reply := "07c37dfeb235213a872192d90877d0cd55635b91 10.0.0.2:6379@16379 slave e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 0 1426238317239 4 connected\n" +
+			"e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 10.0.0.1:6379@16379,redis-0 myself,master - 0 0 1 connected 0-5460 5462 [5461->-292f8b365bb7edb5e285caf0b7e6ddc7265d2f4f]\n"
+
+		nodes, err := ParseClusterNodes(reply)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nodes).To(HaveLen(2))
+
+		Expect(nodes[0].IP).To(Equal("10.0.0.2"))
+		Expect(nodes[0].IsMaster()).To(BeFalse())
+		Expect(nodes[0].MasterID).To(Equal("e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca"))
+
+		Expect(nodes[1].HasFlag("myself")).To(BeTrue())
+		Expect(nodes[1].IsMaster()).To(BeTrue())
+		Expect(nodes[1].MasterID).To(BeEmpty())
+		Expect(nodes[1].Slots).To(Equal([]SlotRange{{Start: 0, End: 5460}, {Start: 5462, End: 5462}}))
+	})
+
+	It("should plan slot moves to new shards", func() {
+		owned := [][]SlotRange{
+			{{Start: 0, End: 8191}},
+			{{Start: 8192, End: 16383}},
+			nil,
+		}
+		Expect(SlotsToMove(owned, 3)).To(Equal(5461))
+
+		move, ok := NextSlotMove(owned, 3)
+		Expect(ok).To(BeTrue())
+		Expect(move).To(Equal(SlotMove{Slot: 16383, From: 1, To: 2}))
+
+		move.Apply(owned)
+		Expect(owned[1]).To(Equal([]SlotRange{{Start: 8192, End: 16382}}))
+		Expect(owned[2]).To(Equal([]SlotRange{{Start: 16383, End: 16383}}))
+
+		for {
+			move, ok := NextSlotMove(owned, 3)
+			if !ok {
+				break
+			}
+			move.Apply(owned)
+		}
+		Expect(SlotsToMove(owned, 3)).To(BeZero())
+		for i, slots := range SplitSlots(3) {
+			size := 0
+			for _, r := range owned[i] {
+				size += r.Size()
+			}
+			Expect(size).To(Equal(slots.Size()))
+		}
+	})
+
+	It("should drain removed shards", func() {
+		owned := [][]SlotRange{
+			{{Start: 0, End: 5461}},
+			{{Start: 5462, End: 10922}},
+			{{Start: 10923, End: 16383}},
+		}
+		Expect(SlotsToMove(owned, 2)).To(Equal(5461))
+
+		for {
+			move, ok := NextSlotMove(owned, 2)
+			if !ok {
+				break
+			}
+			Expect(move.From).To(Equal(2))
+			move.Apply(owned)
+		}
+		Expect(owned[2]).To(BeEmpty())
+	})
+})
@@ -0,0 +1,166 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// fakeServer is an in-process Redis server speaking RESP. Commands are answered
+// with raw RESP replies registered in replies by upper-cased command name,
+// e.g. "INFO" or "CLUSTER NODES" for commands with subcommands.
+type fakeServer struct {
+	listener net.Listener
+	password string
+
+	mu       sync.Mutex
+	replies  map[string]string
+	commands [][]string
+}
+
+func newFakeServer(password string) *fakeServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	server := &fakeServer{
+		listener: listener,
+		password: password,
+		replies: map[string]string{
+			"PING": "+PONG\r\n",
+		},
+	}
+	go server.serve()
+	return server
+}
+
+func (s *fakeServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *fakeServer) Close() {
+	s.listener.Close()
+}
+
+// Reply registers raw RESP reply to command
+func (s *fakeServer) Reply(command string, reply string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replies[command] = reply
+}
+
+// Commands returns commands received by the server
+func (s *fakeServer) Commands() [][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]string{}, s.commands...)
+}
+
+func (s *fakeServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeServer) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	authenticated := s.password == ""
+
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.commands = append(s.commands, args)
+		s.mu.Unlock()
+
+		command := strings.ToUpper(args[0])
+		var reply string
+		switch {
+		case command == "AUTH":
+			if len(args) == 2 && args[1] == s.password {
+				authenticated = true
+				reply = "+OK\r\n"
+			} else {
+				reply = "-WRONGPASS invalid username-password pair\r\n"
+			}
+		case !authenticated:
+			reply = "-NOAUTH Authentication required.\r\n"
+		default:
+			reply = s.reply(args)
+		}
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeServer) reply(args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(args) > 1 {
+		if reply, ok := s.replies[strings.ToUpper(args[0]+" "+args[1])]; ok {
+			return reply
+		}
+	}
+	if reply, ok := s.replies[strings.ToUpper(args[0])]; ok {
+		return reply
+	}
+	return fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0])
+}
+
+// readCommand reads command sent as RESP array of bulk strings
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := readLine(reader)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("unexpected command %q", line)
+	}
+	count, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		arg, err := readReply(reader)
+		if err != nil {
+			return nil, err
+		}
+		s, _ := arg.(string)
+		args = append(args, s)
+	}
+	return args, nil
+}
+
+// bulkString encodes s as RESP bulk string
+func bulkString(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
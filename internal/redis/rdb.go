@@ -0,0 +1,99 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rdbMarkLength is length of the delimiter of RDB sent by diskless replication
+const rdbMarkLength = 40
+
+// SyncRDB requests a snapshot of the dataset the way a replica does with
+// SYNC: the server runs a background save (BGSAVE) for it, sends newlines
+// while the save is in progress and the RDB once it completes. Only the RDB
+// is requested, the server does not stream writes after it. The RDB is
+// copied to w and its size is returned. The transfer is bound by the
+// deadline of ctx only, as saving a large dataset takes a while.
+func (c *Client) SyncRDB(ctx context.Context, w io.Writer) (int64, error) {
+	if _, err := c.Do(ctx, "REPLCONF", "rdb-only", "1"); err != nil {
+		return 0, fmt.Errorf("failed to request RDB only: %w", err)
+	}
+
+	deadline, _ := ctx.Deadline()
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return 0, err
+	}
+	stop := context.AfterFunc(ctx, func() {
+		c.conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	if err := c.writeCommand("SYNC"); err != nil {
+		return 0, err
+	}
+
+	var header string
+	for header == "" {
+		line, err := c.reader.ReadString('\n')
+		if err != nil {
+			return 0, err
+		}
+		// Newlines keep the connection alive until the save completes
+		header = strings.TrimRight(line, "\r\n")
+	}
+
+	switch {
+	case header[0] == '-':
+		return 0, Error(header[1:])
+	case strings.HasPrefix(header, "$EOF:"):
+		// Diskless replication streams RDB of unknown size followed by the mark
+		mark := []byte(header[len("$EOF:"):])
+		if len(mark) != rdbMarkLength {
+			return 0, fmt.Errorf("unexpected RDB delimiter %q", mark)
+		}
+		return copyUntilMark(w, c.reader, mark)
+	case header[0] == '$':
+		size, err := strconv.ParseInt(header[1:], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("unexpected RDB size %q", header)
+		}
+		return io.CopyN(w, c.reader, size)
+	}
+	return 0, fmt.Errorf("unexpected reply %q to SYNC", header)
+}
+
+// copyUntilMark copies reader to w until the data ends with mark, which is not copied
+func copyUntilMark(w io.Writer, reader io.Reader, mark []byte) (int64, error) {
+	var written int64
+	buffer := make([]byte, 32*1024)
+	pending := []byte{}
+	for {
+		n, err := reader.Read(buffer)
+		pending = append(pending, buffer[:n]...)
+		if bytes.HasSuffix(pending, mark) {
+			n, err := w.Write(pending[:len(pending)-len(mark)])
+			return written + int64(n), err
+		}
+		// The last bytes are kept back, they may be the beginning of the mark
+		if len(pending) > len(mark) {
+			flushed := len(pending) - len(mark)
+			n, err := w.Write(pending[:flushed])
+			written += int64(n)
+			if err != nil {
+				return written, err
+			}
+			pending = append(pending[:0], pending[flushed:]...)
+		}
+		if err == io.EOF {
+			return written, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package redis
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"testing/iotest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RDB sync", func() {
+	var server *fakeServer
+	ctx := context.Background()
+	rdb := "REDIS0011\xfa\tredis-ver\x057.2.4\xff"
+	mark := strings.Repeat("0123456789", 4)
+
+	BeforeEach(func() {
+		server = newFakeServer("")
+		server.Reply("REPLCONF", "+OK\r\n")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should copy RDB sent with its size", func() {
+		server.Reply("SYNC", "\n\n$"+strconv.Itoa(len(rdb))+"\r\n"+rdb)
+		client, err := Dial(ctx, server.Addr(), Options{})
+		Expect(err).NotTo(HaveOccurred())
+		defer client.Close()
+
+		var out bytes.Buffer
+		size, err := client.SyncRDB(ctx, &out)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(size).To(Equal(int64(len(rdb))))
+		Expect(out.String()).To(Equal(rdb))
+		Expect(server.Commands()).To(ContainElement([]string{"REPLCONF", "rdb-only", "1"}))
+	})
+
+	It("should copy RDB of diskless sync until the mark", func() {
+		server.Reply("SYNC", "\n$EOF:"+mark+"\r\n"+rdb+mark)
+		client, err := Dial(ctx, server.Addr(), Options{})
+		Expect(err).NotTo(HaveOccurred())
+		defer client.Close()
+
+		var out bytes.Buffer
+		size, err := client.SyncRDB(ctx, &out)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(size).To(Equal(int64(len(rdb))))
+		Expect(out.String()).To(Equal(rdb))
+	})
+
+	It("should find the mark split between reads", func() {
+		var out bytes.Buffer
+		reader := iotest.OneByteReader(strings.NewReader(rdb + mark))
+		size, err := copyUntilMark(&out, reader, []byte(mark))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(size).To(Equal(int64(len(rdb))))
+		Expect(out.String()).To(Equal(rdb))
+	})
+
+	It("should fail on truncated diskless RDB", func() {
+		var out bytes.Buffer
+		_, err := copyUntilMark(&out, strings.NewReader(rdb), []byte(mark))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return error reply to SYNC", func() {
+		server.Reply("SYNC", "-NOPERM this user has no permissions to run the 'sync' command\r\n")
+		client, err := Dial(ctx, server.Addr(), Options{})
+		Expect(err).NotTo(HaveOccurred())
+		defer client.Close()
+
+		_, err = client.SyncRDB(ctx, &bytes.Buffer{})
+		Expect(err).To(MatchError(ContainSubstring("NOPERM")))
+	})
+})
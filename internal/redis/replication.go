@@ -0,0 +1,152 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+const (
+	RoleMaster   = "master"
+	RoleReplica  = "slave"
+	RoleSentinel = "sentinel"
+)
+
+// ReplicationInfo is the server state reported by INFO replication
+type ReplicationInfo struct {
+	Role string
+	// Master the replica is configured to replicate from
+	MasterHost string
+	MasterPort int
+	// MasterLinkUp is true when replica is connected to its master
+	MasterLinkUp         bool
+	MasterSyncInProgress bool
+	// MasterReplOffset is the replication offset of the server itself
+	MasterReplOffset int64
+	// SlaveReplOffset is the offset of master stream processed by replica
+	SlaveReplOffset   int64
+	ConnectedReplicas int
+}
+
+// IsMaster checks if the server accepts writes
+func (info *ReplicationInfo) IsMaster() bool {
+	return info.Role == RoleMaster
+}
+
+// InSync checks if replica is connected to its master and not doing full sync
+func (info *ReplicationInfo) InSync() bool {
+	return info.Role == RoleReplica && info.MasterLinkUp && !info.MasterSyncInProgress
+}
+
+// ParseReplicationInfo converts fields of INFO replication reply
+func ParseReplicationInfo(fields map[string]string) *ReplicationInfo {
+	info := &ReplicationInfo{
+		Role:                 fields["role"],
+		MasterHost:           fields["master_host"],
+		MasterLinkUp:         fields["master_link_status"] == "up",
+		MasterSyncInProgress: fields["master_sync_in_progress"] == "1",
+	}
+	info.MasterPort, _ = strconv.Atoi(fields["master_port"])
+	info.MasterReplOffset, _ = strconv.ParseInt(fields["master_repl_offset"], 10, 64)
+	info.SlaveReplOffset, _ = strconv.ParseInt(fields["slave_repl_offset"], 10, 64)
+	info.ConnectedReplicas, _ = strconv.Atoi(fields["connected_slaves"])
+	return info
+}
+
+// Replication returns replication state of the server
+func (c *Client) Replication(ctx context.Context) (*ReplicationInfo, error) {
+	fields, err := c.Info(ctx, "replication")
+	if err != nil {
+		return nil, err
+	}
+	return ParseReplicationInfo(fields), nil
+}
+
+// Ping checks that the server is able to serve commands
+func (c *Client) Ping(ctx context.Context) error {
+	reply, err := c.String(ctx, "PING")
+	if err != nil {
+		return err
+	}
+	if reply != "PONG" {
+		return fmt.Errorf("unexpected reply %q to PING", reply)
+	}
+	return nil
+}
+
+// ReplicaEntry is a replica entry of ROLE reply of a master
+type ReplicaEntry struct {
+	IP     string
+	Port   int
+	Offset int64
+}
+
+// RoleInfo is the reply of ROLE command
+type RoleInfo struct {
+	Role string
+	// Replication offset of master or offset of master stream processed by replica
+	Offset int64
+	// Master host and port of a replica
+	MasterHost string
+	MasterPort int
+	// State of replica connection to master, e.g. "connected" or "sync"
+	State string
+	// Replicas connected to a master
+	Replicas []ReplicaEntry
+}
+
+// IsMaster checks if the server accepts writes
+func (info *RoleInfo) IsMaster() bool {
+	return info.Role == RoleMaster
+}
+
+// Role returns role of the server as reported by ROLE
+func (c *Client) Role(ctx context.Context) (*RoleInfo, error) {
+	reply, err := c.Do(ctx, "ROLE")
+	if err != nil {
+		return nil, err
+	}
+	return ParseRole(reply)
+}
+
+// ParseRole converts ROLE reply
+func ParseRole(reply interface{}) (*RoleInfo, error) {
+	items, ok := reply.([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("unexpected reply %v to ROLE", reply)
+	}
+	role, _ := items[0].(string)
+	info := &RoleInfo{Role: role}
+
+	switch role {
+	case RoleMaster:
+		if len(items) != 3 {
+			return nil, fmt.Errorf("unexpected reply %v to ROLE", reply)
+		}
+		info.Offset, _ = items[1].(int64)
+		replicas, _ := items[2].([]interface{})
+		for _, item := range replicas {
+			fields, _ := item.([]interface{})
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("unexpected replica entry %v in ROLE reply", item)
+			}
+			replica := ReplicaEntry{}
+			replica.IP, _ = fields[0].(string)
+			port, _ := fields[1].(string)
+			replica.Port, _ = strconv.Atoi(port)
+			offset, _ := fields[2].(string)
+			replica.Offset, _ = strconv.ParseInt(offset, 10, 64)
+			info.Replicas = append(info.Replicas, replica)
+		}
+	case RoleReplica:
+		if len(items) != 5 {
+			return nil, fmt.Errorf("unexpected reply %v to ROLE", reply)
+		}
+		info.MasterHost, _ = items[1].(string)
+		port, _ := items[2].(int64)
+		info.MasterPort = int(port)
+		info.State, _ = items[3].(string)
+		info.Offset, _ = items[4].(int64)
+	}
+	return info, nil
+}
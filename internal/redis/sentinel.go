@@ -0,0 +1,59 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// SentinelMasterAddr returns address of the master monitored under masterSet.
+// Empty host is returned if Sentinel does not monitor masterSet.
+func (c *Client) SentinelMasterAddr(ctx context.Context, masterSet string) (string, error) {
+	reply, err := c.Do(ctx, "SENTINEL", "GET-MASTER-ADDR-BY-NAME", masterSet)
+	if err != nil || reply == nil {
+		return "", err
+	}
+	addr, ok := reply.([]interface{})
+	if !ok || len(addr) != 2 {
+		return "", fmt.Errorf("unexpected reply %v to SENTINEL GET-MASTER-ADDR-BY-NAME", reply)
+	}
+	host, _ := addr[0].(string)
+	return host, nil
+}
+
+// SentinelMonitor starts monitoring master at host:port under masterSet
+func (c *Client) SentinelMonitor(ctx context.Context, masterSet string, host string, port int, quorum int32) error {
+	_, err := c.Do(ctx, "SENTINEL", "MONITOR", masterSet, host, strconv.Itoa(port), strconv.Itoa(int(quorum)))
+	return err
+}
+
+// SentinelRemove stops monitoring masterSet
+func (c *Client) SentinelRemove(ctx context.Context, masterSet string) error {
+	_, err := c.Do(ctx, "SENTINEL", "REMOVE", masterSet)
+	return err
+}
+
+// SentinelSet changes monitoring option of masterSet
+func (c *Client) SentinelSet(ctx context.Context, masterSet string, option string, value string) error {
+	_, err := c.Do(ctx, "SENTINEL", "SET", masterSet, option, value)
+	return err
+}
+
+// SentinelPeers returns number of other Sentinels known to monitor masterSet
+func (c *Client) SentinelPeers(ctx context.Context, masterSet string) (int, error) {
+	reply, err := c.Do(ctx, "SENTINEL", "SENTINELS", masterSet)
+	if err != nil {
+		return 0, err
+	}
+	peers, ok := reply.([]interface{})
+	if !ok {
+		return 0, fmt.Errorf("unexpected reply %v to SENTINEL SENTINELS", reply)
+	}
+	return len(peers), nil
+}
+
+// SentinelReset forgets replicas and Sentinels of masterSet, they are rediscovered afterwards
+func (c *Client) SentinelReset(ctx context.Context, masterSet string) error {
+	_, err := c.Do(ctx, "SENTINEL", "RESET", masterSet)
+	return err
+}
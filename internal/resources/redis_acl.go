@@ -0,0 +1,81 @@
+package resources
+
+import (
+	"path"
+	"strings"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	metadata "github.com/avekrivoy/redis-operator/internal/metadata"
+	redisclient "github.com/avekrivoy/redis-operator/internal/redis"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ACLFileKey is the key of ACL secret holding the ACL file
+	ACLFileKey     = "users.acl"
+	redisACLVolume = "redis-acl"
+	redisACLPath   = "/opt/bitnami/redis/mounted-etc/acl"
+)
+
+// UserACLRules returns ACL rules granting the user access declared in RedisUser spec
+func UserACLRules(spec *cachev1alpha1.RedisUserSpec, password string) []string {
+	rules := []string{"on", "#" + redisclient.PasswordHash(password)}
+	for _, key := range spec.Keys {
+		rules = append(rules, "~"+key)
+	}
+	for _, channel := range spec.Channels {
+		rules = append(rules, "&"+channel)
+	}
+	for _, category := range spec.Categories {
+		rules = append(rules, "+@"+category)
+	}
+	for _, command := range spec.Commands {
+		rules = append(rules, "+"+command)
+	}
+	return rules
+}
+
+// DefaultUserACLRules returns ACL rules of the default user accepting any of
+// passwords. Without passwords the default user accepts any client.
+func DefaultUserACLRules(passwords []string) []string {
+	rules := []string{"on"}
+	if len(passwords) == 0 {
+		rules = append(rules, "nopass")
+	}
+	for _, password := range passwords {
+		rules = append(rules, "#"+redisclient.PasswordHash(password))
+	}
+	return append(rules, "~*", "&*", "+@all")
+}
+
+// ACLFileLine returns ACL file entry of the user
+func ACLFileLine(username string, rules []string) string {
+	return "user " + username + " " + strings.Join(rules, " ")
+}
+
+// mountACLFile mounts ACL file rendered by the operator into Redis container,
+// so users declared by RedisUser resources survive pod restarts
+func (builder *RedisResourceBuilder) mountACLFile(podSpec *corev1.PodSpec) {
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: redisACLVolume,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: metadata.RedisACLSecretName(builder.Instance.Name),
+			},
+		},
+	})
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name != redisContainerName {
+			continue
+		}
+		podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, corev1.VolumeMount{
+			Name:      redisACLVolume,
+			MountPath: redisACLPath,
+			ReadOnly:  true,
+		})
+		podSpec.Containers[i].Env = append(podSpec.Containers[i].Env, corev1.EnvVar{
+			Name:  "REDIS_ACLFILE",
+			Value: path.Join(redisACLPath, ACLFileKey),
+		})
+	}
+}
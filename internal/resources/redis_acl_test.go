@@ -0,0 +1,58 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	redisclient "github.com/avekrivoy/redis-operator/internal/redis"
+)
+
+var _ = Describe("Redis ACL", func() {
+	It("should render rules of RedisUser", func() {
+		spec := &cachev1alpha1.RedisUserSpec{
+			Redis:      "redis",
+			Commands:   []string{"client|setname"},
+			Categories: []string{"read", "pubsub"},
+			Keys:       []string{"orders:*"},
+			Channels:   []string{"events:*"},
+		}
+		Expect(ACLFileLine("orders", UserACLRules(spec, "secret"))).To(Equal(
+			"user orders on #" + redisclient.PasswordHash("secret") + " ~orders:* &events:* +@read +@pubsub +client|setname"))
+	})
+
+	It("should render the default user", func() {
+		Expect(DefaultUserACLRules(nil)).To(Equal([]string{"on", "nopass", "~*", "&*", "+@all"}))
+		Expect(DefaultUserACLRules([]string{"new", "old"})).To(Equal([]string{"on",
+			"#" + redisclient.PasswordHash("new"), "#" + redisclient.PasswordHash("old"), "~*", "&*", "+@all"}))
+	})
+
+	It("should load ACL file in Redis pods", func() {
+		builder := &RedisResourceBuilder{Instance: newTestInstance(), Scheme: newTestScheme()}
+		podSpec := updated(builder.RedisMasterDeployment()).(*appsv1.Deployment).Spec.Template.Spec
+		Expect(podSpec.Volumes).To(ContainElement(HaveField("Secret.SecretName", builder.Instance.Name+"-acl")))
+		Expect(podSpec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+			Name:  "REDIS_ACLFILE",
+			Value: "/opt/bitnami/redis/mounted-etc/acl/users.acl",
+		}))
+	})
+})
@@ -12,6 +12,16 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
+// Keys of the generated auth secret
+const (
+	// Password Redis pods and clients use
+	AuthPasswordKey = "REDIS_PASSWORD"
+	// Password still accepted by Redis pods until rotation is completed
+	AuthPreviousPasswordKey = "REDIS_PREVIOUS_PASSWORD"
+	// Password being added to Redis pods by rotation
+	AuthNextPasswordKey = "REDIS_NEXT_PASSWORD"
+)
+
 type RedisAuthSecretBuilder struct {
 	*RedisResourceBuilder
 }
@@ -66,14 +76,17 @@ func (builder *RedisAuthSecretBuilder) Update(object client.Object) error {
 	}
 	labels := metadata.ResourceLabels(builder.Instance.Name, secretLabels)
 
-	redisPwd, err := randomEncodedString(24)
-	if err != nil {
-		return err
-	}
-
 	secret.Labels = labels
-	secret.Data = map[string][]byte{
-		"REDIS_PASSWORD": []byte(redisPwd),
+	// Password is generated only once, it is changed by password rotation
+	if len(secret.Data[AuthPasswordKey]) == 0 {
+		redisPwd, err := GeneratePassword()
+		if err != nil {
+			return err
+		}
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		secret.Data[AuthPasswordKey] = []byte(redisPwd)
 	}
 
 	if err := controllerutil.SetControllerReference(builder.Instance, secret, builder.Scheme); err != nil {
@@ -83,8 +96,14 @@ func (builder *RedisAuthSecretBuilder) Update(object client.Object) error {
 	return nil
 }
 
-func (builder *RedisAuthSecretBuilder) IsDeployed() bool {
-	return builder.Instance.Spec.Common.Auth.ExistingSecret == ""
+func (builder *RedisAuthSecretBuilder) Outcome() Outcome {
+	auth := builder.Instance.Spec.Common.Auth
+	return deployedIf(auth.IsEnabled() && auth.ExistingSecret == "")
+}
+
+// GeneratePassword returns random password for Redis
+func GeneratePassword() (string, error) {
+	return randomEncodedString(24)
 }
 
 func randomEncodedString(dataLen int) (string, error) {
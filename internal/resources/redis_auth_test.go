@@ -0,0 +1,111 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	"github.com/avekrivoy/redis-operator/internal/metadata"
+)
+
+var _ = Describe("Redis authentication", func() {
+	var builder *RedisResourceBuilder
+
+	BeforeEach(func() {
+		instance := newTestInstance()
+		instance.Spec.Replica.Count = 1
+		builder = &RedisResourceBuilder{Instance: instance, Scheme: newTestScheme()}
+	})
+
+	redisContainer := func(resourceBuilder ResourceBuilder) corev1.Container {
+		return updated(resourceBuilder).(*appsv1.Deployment).Spec.Template.Spec.Containers[0]
+	}
+
+	It("should read password from the auth secret", func() {
+		Expect(builder.RedisAuthSecret().Outcome()).To(Equal(OutcomeDeploy))
+
+		container := redisContainer(builder.RedisReplicaDeployment())
+		Expect(container.EnvFrom).To(HaveLen(1))
+		Expect(container.Env).To(ContainElement(HaveField("Name", "REDIS_MASTER_PASSWORD")))
+		Expect(container.Env).NotTo(ContainElement(HaveField("Name", "ALLOW_EMPTY_PASSWORD")))
+	})
+
+	It("should run passwordless Redis when auth is disabled", func() {
+		builder.Instance.Spec.Common.Auth.Enabled = ptr.To(false)
+		Expect(builder.RedisAuthSecret().Outcome()).To(Equal(OutcomeRemove))
+
+		for _, resourceBuilder := range []ResourceBuilder{builder.RedisMasterDeployment(), builder.RedisReplicaDeployment()} {
+			container := redisContainer(resourceBuilder)
+			Expect(container.EnvFrom).To(BeEmpty())
+			Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "ALLOW_EMPTY_PASSWORD", Value: "yes"}))
+			Expect(container.Env).NotTo(ContainElement(HaveField("Name", "REDIS_MASTER_PASSWORD")))
+		}
+	})
+})
+
+var _ = Describe("Redis password rotation", func() {
+	var builder *RedisResourceBuilder
+
+	BeforeEach(func() {
+		instance := newTestInstance()
+		instance.Spec.Replica.Count = 1
+		builder = &RedisResourceBuilder{Instance: instance, Scheme: newTestScheme()}
+	})
+
+	It("should keep the generated password", func() {
+		secretBuilder := builder.RedisAuthSecret()
+		secret := updated(secretBuilder).(*corev1.Secret)
+		password := secret.Data[AuthPasswordKey]
+		Expect(password).NotTo(BeEmpty())
+
+		secret.Data[AuthPreviousPasswordKey] = []byte("previous")
+		Expect(secretBuilder.Update(secret)).To(Succeed())
+		Expect(secret.Data).To(HaveKeyWithValue(AuthPasswordKey, password))
+		Expect(secret.Data).To(HaveKeyWithValue(AuthPreviousPasswordKey, []byte("previous")))
+	})
+
+	It("should restart replicas once per rotation", func() {
+		replicaTemplate := func() corev1.PodTemplateSpec {
+			return updated(builder.RedisReplicaDeployment()).(*appsv1.Deployment).Spec.Template
+		}
+		Expect(replicaTemplate().Annotations).NotTo(HaveKey(metadata.PasswordRotationAnnotation))
+
+		startTime := metav1.NewTime(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+		builder.Instance.Status.Auth = &cachev1alpha1.RedisAuthStatus{
+			Rotation: &cachev1alpha1.RedisPasswordRotationStatus{
+				Phase:     cachev1alpha1.PasswordRotationPhaseAddingPassword,
+				StartTime: startTime,
+			},
+		}
+		Expect(replicaTemplate().Annotations).NotTo(HaveKey(metadata.PasswordRotationAnnotation))
+
+		builder.Instance.Status.Auth.Rotation.Phase = cachev1alpha1.PasswordRotationPhaseRestartingReplicas
+		Expect(replicaTemplate().Annotations).To(HaveKeyWithValue(metadata.PasswordRotationAnnotation, "2024-05-01T10:00:00Z"))
+
+		builder.Instance.Status.Auth = &cachev1alpha1.RedisAuthStatus{LastRotationTime: &startTime}
+		Expect(replicaTemplate().Annotations).To(HaveKeyWithValue(metadata.PasswordRotationAnnotation, "2024-05-01T10:00:00Z"))
+	})
+})
@@ -0,0 +1,14 @@
+package resources
+
+import (
+	"fmt"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	metadata "github.com/avekrivoy/redis-operator/internal/metadata"
+)
+
+// CloneSourceHost returns address of master Service of the source Redis of cloneFrom
+func CloneSourceHost(instance *cachev1alpha1.Redis) string {
+	source := instance.CloneSource()
+	return fmt.Sprintf("%s.%s.svc", metadata.RedisServiceName(source.Name, metadata.RedisMasterComponent()), source.Namespace)
+}
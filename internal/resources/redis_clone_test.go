@@ -0,0 +1,66 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+)
+
+var _ = Describe("Redis clone", func() {
+	var builder *RedisResourceBuilder
+
+	BeforeEach(func() {
+		builder = &RedisResourceBuilder{Instance: newTestInstance(), Scheme: newTestScheme()}
+		builder.Instance.Spec.Master.Kind = cachev1alpha1.KindStatefulSet
+		builder.Instance.Spec.CloneFrom = &cachev1alpha1.RedisCloneSource{Name: "production", Namespace: "cache"}
+	})
+
+	masterTemplate := func() corev1.PodTemplateSpec {
+		return updated(builder.RedisMasterStatefulSet()).(*appsv1.StatefulSet).Spec.Template
+	}
+
+	It("should run master pods as masters, which the operator points to the source", func() {
+		env := masterTemplate().Spec.Containers[0].Env
+		Expect(env).To(ContainElement(corev1.EnvVar{Name: "REDIS_REPLICATION_MODE", Value: "master"}))
+		Expect(env).NotTo(ContainElement(HaveField("Name", "REDIS_MASTER_HOST")))
+		Expect(CloneSourceHost(builder.Instance)).To(Equal("production-redis-master.cache.svc"))
+	})
+
+	It("should default source namespace to the namespace of the clone", func() {
+		builder.Instance.Spec.CloneFrom.Namespace = ""
+		Expect(CloneSourceHost(builder.Instance)).To(Equal("production-redis-master.default.svc"))
+	})
+
+	It("should not restart master pods once detached", func() {
+		builder.Instance.Status.Clone = &cachev1alpha1.RedisCloneStatus{Phase: cachev1alpha1.ClonePhaseSyncing}
+		syncing := masterTemplate()
+		builder.Instance.Status.Clone.Phase = cachev1alpha1.ClonePhaseCompleted
+		Expect(masterTemplate()).To(Equal(syncing))
+	})
+
+	It("should keep replicas replicating from master of the clone", func() {
+		env := updated(builder.RedisReplicaDeployment()).(*appsv1.Deployment).Spec.Template.Spec.Containers[0].Env
+		Expect(env).To(ContainElement(corev1.EnvVar{Name: "REDIS_MASTER_HOST", Value: "test-redis-redis-master"}))
+		Expect(env).To(ContainElement(HaveField("ValueFrom.SecretKeyRef.Name", "test-redis-auth-secret")))
+	})
+})
@@ -0,0 +1,83 @@
+package resources
+
+import (
+	"fmt"
+	"strconv"
+
+	metadata "github.com/avekrivoy/redis-operator/internal/metadata"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// RedisClusterShardBuilder renders StatefulSet of a single Redis Cluster shard.
+// Pod with ordinal 0 becomes shard master on bootstrap, the rest are its replicas.
+type RedisClusterShardBuilder struct {
+	*RedisResourceBuilder
+	Shard int32
+}
+
+func (builder *RedisResourceBuilder) RedisClusterShard(shard int32) *RedisClusterShardBuilder {
+	return &RedisClusterShardBuilder{builder, shard}
+}
+
+// RedisClusterShards returns builders of all shards in the cluster including
+// shards being drained by resharding
+func (builder *RedisResourceBuilder) RedisClusterShards() []ResourceBuilder {
+	builders := []ResourceBuilder{}
+	for shard := int32(0); shard < builder.Instance.ClusterShards(); shard++ {
+		builders = append(builders, builder.RedisClusterShard(shard))
+	}
+	return builders
+}
+
+func (builder *RedisClusterShardBuilder) Build() (client.Object, error) {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      metadata.RedisClusterShardName(builder.Instance.Name, builder.Shard),
+			Namespace: builder.Instance.Namespace,
+		},
+	}, nil
+}
+
+func (builder *RedisClusterShardBuilder) Update(object client.Object) error {
+	component := metadata.RedisClusterComponent()
+
+	statefulSetLabels := metadata.Label{
+		"app.kubernetes.io/component": component,
+		metadata.ShardLabel:           strconv.Itoa(int(builder.Shard)),
+	}
+
+	labels := metadata.ResourceLabels(builder.Instance.Name, statefulSetLabels)
+	podCount := builder.Instance.Spec.Cluster.ReplicasPerShard + 1
+
+	statefulSet := object.(*appsv1.StatefulSet)
+	statefulSet.ObjectMeta.Labels = labels
+	statefulSet.Spec.Replicas = &podCount
+	statefulSet.Spec.ServiceName = metadata.RedisServiceName(builder.Instance.Name, component)
+	statefulSet.Spec.Template.ObjectMeta.Labels = labels
+	statefulSet.Spec.Selector = &metav1.LabelSelector{
+		MatchLabels: metadata.ShardLabelSelector(builder.Instance.Name, builder.Shard),
+	}
+	statefulSet.Spec.Template.Spec = builder.redisClusterPodSpec()
+	builder.setConfigChecksumAnnotation(&statefulSet.Spec.Template, component)
+	mountRedisData(&statefulSet.Spec.Template.Spec)
+
+	// Volume claim templates are immutable, set them only on creation
+	if statefulSet.CreationTimestamp.IsZero() {
+		statefulSet.Spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{
+			builder.redisDataClaim(builder.Instance.Spec.Cluster.Persistence, labels),
+		}
+	}
+
+	if err := controllerutil.SetControllerReference(builder.Instance, statefulSet, builder.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+	return nil
+}
+
+func (builder *RedisClusterShardBuilder) Outcome() Outcome {
+	return deployedIf(builder.isClusterMode())
+}
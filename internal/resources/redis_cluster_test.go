@@ -0,0 +1,55 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+)
+
+var _ = Describe("Redis Cluster builders", func() {
+	var builder *RedisResourceBuilder
+
+	BeforeEach(func() {
+		instance := newTestInstance()
+		instance.Spec.Mode = cachev1alpha1.ModeCluster
+		instance.Spec.Cluster.Shards = 2
+		instance.Spec.Cluster.ReplicasPerShard = 1
+		builder = &RedisResourceBuilder{Instance: instance, Scheme: newTestScheme()}
+	})
+
+	It("should render a StatefulSet per shard", func() {
+		Expect(builder.RedisClusterShards()).To(HaveLen(2))
+		Expect(builder.RedisMasterDeployment().Outcome()).To(Equal(OutcomeRemove))
+	})
+
+	It("should keep drained shards until resharding completes", func() {
+		builder.Instance.Status.Cluster = &cachev1alpha1.RedisClusterStatus{
+			Resharding: &cachev1alpha1.RedisReshardingStatus{
+				Step:       cachev1alpha1.ReshardingStepMigratingSlots,
+				FromShards: 4,
+				ToShards:   2,
+			},
+		}
+		Expect(builder.RedisClusterShards()).To(HaveLen(4))
+
+		builder.Instance.Status.Cluster.Resharding = nil
+		Expect(builder.RedisClusterShards()).To(HaveLen(2))
+	})
+})
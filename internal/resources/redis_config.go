@@ -0,0 +1,285 @@
+package resources
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	metadata "github.com/avekrivoy/redis-operator/internal/metadata"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// runtimeConfigDirectives are redis.conf directives Redis applies with CONFIG SET
+// without restart. Directives starting with these prefixes are runtime too.
+var runtimeConfigDirectives = []string{
+	"maxmemory", "maxmemory-policy", "maxmemory-samples", "maxmemory-eviction-tenacity",
+	"maxclients", "timeout", "tcp-keepalive", "hz", "dynamic-hz", "loglevel",
+	"slowlog-log-slower-than", "slowlog-max-len", "latency-monitor-threshold", "latency-tracking",
+	"latency-tracking-info-percentiles", "notify-keyspace-events", "busy-reply-threshold", "lua-time-limit",
+	"save", "appendonly", "appendfsync", "auto-aof-rewrite-percentage", "auto-aof-rewrite-min-size",
+	"repl-backlog-size", "repl-backlog-ttl", "repl-timeout", "repl-diskless-sync", "repl-diskless-sync-delay",
+	"min-replicas-to-write", "min-replicas-max-lag", "replica-serve-stale-data", "replica-read-only",
+	"client-output-buffer-limit", "client-query-buffer-limit", "proto-max-bulk-len",
+	"activedefrag", "active-defrag-", "lazyfree-", "replica-lazy-flush",
+	"hash-max-", "list-max-", "set-max-", "zset-max-", "stream-node-max-",
+}
+
+// runtimeConfigDefaults are values runtime directives have in pods started
+// without them, Redis defaults except appendonly enabled by Bitnami image.
+// Directives removed from config are reset to them with CONFIG SET.
+var runtimeConfigDefaults = map[string]string{
+	"maxmemory":                         "0",
+	"maxmemory-policy":                  "noeviction",
+	"maxmemory-samples":                 "5",
+	"maxmemory-eviction-tenacity":       "10",
+	"maxclients":                        "10000",
+	"timeout":                           "0",
+	"tcp-keepalive":                     "300",
+	"hz":                                "10",
+	"dynamic-hz":                        "yes",
+	"loglevel":                          "notice",
+	"slowlog-log-slower-than":           "10000",
+	"slowlog-max-len":                   "128",
+	"latency-monitor-threshold":         "0",
+	"latency-tracking":                  "yes",
+	"latency-tracking-info-percentiles": "50 99 99.9",
+	"notify-keyspace-events":            "",
+	"busy-reply-threshold":              "5000",
+	"lua-time-limit":                    "5000",
+	"save":                              "3600 1 300 100 60 10000",
+	"appendonly":                        "yes",
+	"appendfsync":                       "everysec",
+	"auto-aof-rewrite-percentage":       "100",
+	"auto-aof-rewrite-min-size":         "67108864",
+	"repl-backlog-size":                 "1048576",
+	"repl-backlog-ttl":                  "3600",
+	"repl-timeout":                      "60",
+	"repl-diskless-sync":                "yes",
+	"repl-diskless-sync-delay":          "5",
+	"min-replicas-to-write":             "0",
+	"min-replicas-max-lag":              "10",
+	"replica-serve-stale-data":          "yes",
+	"replica-read-only":                 "yes",
+	"client-output-buffer-limit":        "normal 0 0 0 replica 268435456 67108864 60 pubsub 33554432 8388608 60",
+	"client-query-buffer-limit":         "1073741824",
+	"proto-max-bulk-len":                "536870912",
+	"activedefrag":                      "no",
+	"active-defrag-ignore-bytes":        "104857600",
+	"active-defrag-threshold-lower":     "10",
+	"active-defrag-threshold-upper":     "100",
+	"active-defrag-cycle-min":           "1",
+	"active-defrag-cycle-max":           "25",
+	"active-defrag-max-scan-fields":     "1000",
+	"lazyfree-lazy-eviction":            "no",
+	"lazyfree-lazy-expire":              "no",
+	"lazyfree-lazy-server-del":          "no",
+	"lazyfree-lazy-user-del":            "no",
+	"lazyfree-lazy-user-flush":          "no",
+	"replica-lazy-flush":                "no",
+	"hash-max-listpack-entries":         "128",
+	"hash-max-listpack-value":           "64",
+	"list-max-listpack-size":            "-2",
+	"set-max-intset-entries":            "512",
+	"set-max-listpack-entries":          "128",
+	"set-max-listpack-value":            "64",
+	"zset-max-listpack-entries":         "128",
+	"zset-max-listpack-value":           "64",
+	"stream-node-max-bytes":             "4096",
+	"stream-node-max-entries":           "100",
+}
+
+const (
+	redisConfigVolume = "redis-config"
+	redisConfigPath   = "/opt/bitnami/redis/mounted-etc/config"
+	redisConfigFile   = "redis.conf"
+)
+
+type RedisConfigMapBuilder struct {
+	*RedisResourceBuilder
+}
+
+func (builder *RedisResourceBuilder) RedisConfigMap() *RedisConfigMapBuilder {
+	return &RedisConfigMapBuilder{builder}
+}
+
+func (builder *RedisConfigMapBuilder) Build() (client.Object, error) {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      metadata.RedisConfigMapName(builder.Instance.Name),
+			Namespace: builder.Instance.Namespace,
+		},
+	}, nil
+}
+
+func (builder *RedisConfigMapBuilder) Update(object client.Object) error {
+	configMap := object.(*corev1.ConfigMap)
+
+	configMapLabels := metadata.Label{
+		"app.kubernetes.io/component": metadata.DefaultComponent,
+	}
+	configMap.Labels = metadata.ResourceLabels(builder.Instance.Name, configMapLabels)
+
+	// Every component gets its own file with common directives and its overrides
+	configMap.Data = map[string]string{}
+	for _, component := range builder.ConfigComponents() {
+		configMap.Data[ConfigFileKey(component)] = RenderConfig(builder.RedisConfig(component))
+	}
+
+	if err := controllerutil.SetControllerReference(builder.Instance, configMap, builder.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+	return nil
+}
+
+func (builder *RedisConfigMapBuilder) Outcome() Outcome {
+	return OutcomeDeploy
+}
+
+// ConfigFileKey returns key of the ConfigMap holding redis.conf of component pods
+func ConfigFileKey(component string) string {
+	return component + ".conf"
+}
+
+// ConfigComponents returns Redis components run in the current mode
+func (builder *RedisResourceBuilder) ConfigComponents() []string {
+	if builder.isClusterMode() {
+		return []string{metadata.RedisClusterComponent()}
+	}
+	return []string{metadata.RedisMasterComponent(), metadata.RedisReplicaComponent()}
+}
+
+// RedisConfig returns common directives merged with overrides of the component
+// and maxmemory derived from the memory limit
+func (builder *RedisResourceBuilder) RedisConfig(component string) cachev1alpha1.RedisConfig {
+	spec := builder.Instance.Spec
+	var overrides cachev1alpha1.RedisConfig
+	switch component {
+	case metadata.RedisMasterComponent():
+		overrides = spec.Master.Config
+	case metadata.RedisReplicaComponent():
+		overrides = spec.Replica.Config
+	case metadata.RedisClusterComponent():
+		overrides = spec.Cluster.Config
+	}
+
+	config := cachev1alpha1.RedisConfig{}
+	for name, value := range spec.Common.Config {
+		config[name] = value
+	}
+	for name, value := range overrides {
+		config[name] = value
+	}
+	builder.setDerivedMaxMemory(config, component)
+	return config
+}
+
+// RenderConfig returns redis.conf with directives sorted by name
+func RenderConfig(config cachev1alpha1.RedisConfig) string {
+	names := make([]string, 0, len(config))
+	for name := range config {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var conf strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&conf, "%s %s\n", name, config[name])
+	}
+	return conf.String()
+}
+
+// IsRuntimeConfigDirective checks if Redis applies directive with CONFIG SET
+// without restart
+func IsRuntimeConfigDirective(name string) bool {
+	name = strings.ToLower(name)
+	for _, directive := range runtimeConfigDirectives {
+		if name == directive || (strings.HasSuffix(directive, "-") && strings.HasPrefix(name, directive)) {
+			return true
+		}
+	}
+	return false
+}
+
+// RuntimeConfigDefault returns value the runtime directive has in pods started
+// without it, false if it is not known
+func RuntimeConfigDefault(name string) (string, bool) {
+	value, ok := runtimeConfigDefaults[strings.ToLower(name)]
+	return value, ok
+}
+
+// SplitConfig splits config into directives applied at runtime and directives
+// which require restart
+func SplitConfig(config cachev1alpha1.RedisConfig) (runtime cachev1alpha1.RedisConfig, restart cachev1alpha1.RedisConfig) {
+	runtime = cachev1alpha1.RedisConfig{}
+	restart = cachev1alpha1.RedisConfig{}
+	for name, value := range config {
+		if IsRuntimeConfigDirective(name) {
+			runtime[name] = value
+		} else {
+			restart[name] = value
+		}
+	}
+	return runtime, restart
+}
+
+// ConfigChecksum returns checksum of config, empty for empty config
+func ConfigChecksum(config cachev1alpha1.RedisConfig) string {
+	if len(config) == 0 {
+		return ""
+	}
+	checksum := sha256.Sum256([]byte(RenderConfig(config)))
+	return hex.EncodeToString(checksum[:])
+}
+
+// mountConfig mounts redis.conf of the component into Redis container and
+// includes it after the configuration generated by Bitnami image, so
+// directives of the spec take precedence over image defaults
+func (builder *RedisResourceBuilder) mountConfig(podSpec *corev1.PodSpec, component string) {
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: redisConfigVolume,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: metadata.RedisConfigMapName(builder.Instance.Name),
+				},
+				Items: []corev1.KeyToPath{{Key: ConfigFileKey(component), Path: redisConfigFile}},
+			},
+		},
+	})
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		if container.Name != redisContainerName {
+			continue
+		}
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      redisConfigVolume,
+			MountPath: redisConfigPath,
+			ReadOnly:  true,
+		})
+		setExtraFlags(container, "--include "+path.Join(redisConfigPath, redisConfigFile))
+	}
+}
+
+// setConfigChecksumAnnotation annotates pod template with checksum of redis.conf
+// directives of the component which require restart, so pods are restarted
+// only when they change. Runtime directives are applied to running pods by the
+// controller. Pods without such directives are not annotated.
+func (builder *RedisResourceBuilder) setConfigChecksumAnnotation(template *corev1.PodTemplateSpec, component string) {
+	_, restart := SplitConfig(builder.RedisConfig(component))
+	checksum := ConfigChecksum(restart)
+	if checksum == "" {
+		delete(template.Annotations, metadata.ConfigChecksumAnnotation)
+		return
+	}
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[metadata.ConfigChecksumAnnotation] = checksum
+}
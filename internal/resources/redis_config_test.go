@@ -0,0 +1,122 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	"github.com/avekrivoy/redis-operator/internal/metadata"
+)
+
+var _ = Describe("Redis config", func() {
+	var builder *RedisResourceBuilder
+
+	BeforeEach(func() {
+		instance := newTestInstance()
+		instance.Spec.Common.Config = cachev1alpha1.RedisConfig{
+			"maxmemory-policy": "allkeys-lru",
+			"appendonly":       "yes",
+			"databases":        "32",
+		}
+		instance.Spec.Replica.Config = cachev1alpha1.RedisConfig{
+			"appendonly": "no",
+		}
+		builder = &RedisResourceBuilder{Instance: instance, Scheme: newTestScheme()}
+	})
+
+	It("should render config of every component with its overrides", func() {
+		configMap := updated(builder.RedisConfigMap()).(*corev1.ConfigMap)
+		Expect(configMap.Name).To(Equal("test-redis-redis-config"))
+		Expect(configMap.Data).To(Equal(map[string]string{
+			"redis-master.conf":  "appendonly yes\ndatabases 32\nmaxmemory-policy allkeys-lru\n",
+			"redis-replica.conf": "appendonly no\ndatabases 32\nmaxmemory-policy allkeys-lru\n",
+		}))
+	})
+
+	It("should include config in Redis pods", func() {
+		template := updated(builder.RedisReplicaDeployment()).(*appsv1.Deployment).Spec.Template
+		Expect(template.Spec.Volumes).To(ContainElement(HaveField("ConfigMap.Items", ConsistOf(corev1.KeyToPath{
+			Key:  "redis-replica.conf",
+			Path: "redis.conf",
+		}))))
+		Expect(template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{
+			Name:  "REDIS_EXTRA_FLAGS",
+			Value: "--include /opt/bitnami/redis/mounted-etc/config/redis.conf",
+		}))
+		Expect(template.Annotations).To(HaveKey(metadata.ConfigChecksumAnnotation))
+	})
+
+	It("should restart pods only when their directives requiring restart change", func() {
+		checksum := updated(builder.RedisMasterDeployment()).(*appsv1.Deployment).Spec.Template.Annotations[metadata.ConfigChecksumAnnotation]
+
+		builder.Instance.Spec.Replica.Config["databases"] = "64"
+		Expect(updated(builder.RedisMasterDeployment()).(*appsv1.Deployment).Spec.Template.Annotations).To(
+			HaveKeyWithValue(metadata.ConfigChecksumAnnotation, checksum))
+
+		builder.Instance.Spec.Master.Config = cachev1alpha1.RedisConfig{"timeout": "300", "maxmemory": "1gb"}
+		Expect(updated(builder.RedisMasterDeployment()).(*appsv1.Deployment).Spec.Template.Annotations).To(
+			HaveKeyWithValue(metadata.ConfigChecksumAnnotation, checksum))
+
+		builder.Instance.Spec.Master.Config = cachev1alpha1.RedisConfig{"io-threads": "4"}
+		Expect(updated(builder.RedisMasterDeployment()).(*appsv1.Deployment).Spec.Template.Annotations).NotTo(
+			HaveKeyWithValue(metadata.ConfigChecksumAnnotation, checksum))
+	})
+
+	It("should not annotate pods without directives requiring restart", func() {
+		delete(builder.Instance.Spec.Common.Config, "databases")
+		Expect(updated(builder.RedisMasterDeployment()).(*appsv1.Deployment).Spec.Template.Annotations).NotTo(
+			HaveKey(metadata.ConfigChecksumAnnotation))
+	})
+
+	It("should know defaults of runtime directives", func() {
+		for _, name := range runtimeConfigDirectives {
+			if strings.HasSuffix(name, "-") {
+				continue
+			}
+			_, ok := RuntimeConfigDefault(name)
+			Expect(ok).To(BeTrue(), name)
+		}
+		for name := range runtimeConfigDefaults {
+			Expect(IsRuntimeConfigDirective(name)).To(BeTrue(), name)
+		}
+		value, ok := RuntimeConfigDefault("Maxmemory-Policy")
+		Expect(ok).To(BeTrue())
+		Expect(value).To(Equal("noeviction"))
+		_, ok = RuntimeConfigDefault("hash-max-ziplist-entries")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should split runtime directives from directives requiring restart", func() {
+		runtime, restart := SplitConfig(cachev1alpha1.RedisConfig{
+			"maxmemory":                 "1gb",
+			"Slowlog-Max-Len":           "256",
+			"active-defrag-cycle-min":   "5",
+			"hash-max-listpack-entries": "256",
+			"io-threads":                "4",
+			"databases":                 "32",
+		})
+		Expect(runtime).To(HaveLen(4))
+		Expect(restart).To(Equal(cachev1alpha1.RedisConfig{"io-threads": "4", "databases": "32"}))
+	})
+})
@@ -41,7 +41,7 @@ func (builder *RedisMasterServiceBuilder) Update(object client.Object) error {
 
 	svc.ObjectMeta.Labels = svcLabels
 	svc.Spec = corev1.ServiceSpec{
-		Selector: metadata.LabelSelector(builder.Instance.Name, component),
+		Selector: builder.serviceSelector(component, builder.Instance.Spec.Master.Kind, metadata.RoleMaster),
 		Ports: []corev1.ServicePort{
 			{
 				Port:     6379,
@@ -58,6 +58,6 @@ func (builder *RedisMasterServiceBuilder) Update(object client.Object) error {
 	return nil
 }
 
-func (builder *RedisMasterServiceBuilder) IsDeployed() bool {
-	return builder.Instance.Spec.Master.Count > 0
+func (builder *RedisMasterServiceBuilder) Outcome() Outcome {
+	return deployedIf(!builder.isClusterMode() && builder.Instance.Spec.Master.Count > 0)
 }
@@ -0,0 +1,73 @@
+package resources
+
+import (
+	"fmt"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	metadata "github.com/avekrivoy/redis-operator/internal/metadata"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+type RedisMasterStatefulSetBuilder struct {
+	*RedisResourceBuilder
+}
+
+func (builder *RedisResourceBuilder) RedisMasterStatefulSet() *RedisMasterStatefulSetBuilder {
+	return &RedisMasterStatefulSetBuilder{builder}
+}
+
+func (builder *RedisMasterStatefulSetBuilder) Build() (client.Object, error) {
+	component := metadata.RedisMasterComponent()
+	statefulSetName := fmt.Sprintf("%s-%s", builder.Instance.Name, component)
+
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      statefulSetName,
+			Namespace: builder.Instance.Namespace,
+		},
+	}, nil
+}
+
+func (builder *RedisMasterStatefulSetBuilder) Update(object client.Object) error {
+	component := metadata.RedisMasterComponent()
+
+	statefulSetLabels := metadata.Label{
+		"app.kubernetes.io/component": component,
+	}
+
+	labels := metadata.ResourceLabels(builder.Instance.Name, statefulSetLabels)
+
+	statefulSet := object.(*appsv1.StatefulSet)
+	statefulSet.ObjectMeta.Labels = labels
+	statefulSet.Spec.Replicas = &builder.Instance.Spec.Master.Count
+	statefulSet.Spec.ServiceName = metadata.RedisServiceName(builder.Instance.Name, component)
+	statefulSet.Spec.Template.ObjectMeta.Labels = builder.podLabels(component, cachev1alpha1.KindStatefulSet)
+	statefulSet.Spec.Selector = &metav1.LabelSelector{
+		MatchLabels: metadata.LabelSelector(builder.Instance.Name, component),
+	}
+	statefulSet.Spec.Template.Spec = builder.redisMasterPodSpec()
+	builder.setConfigChecksumAnnotation(&statefulSet.Spec.Template, component)
+	mountRedisData(&statefulSet.Spec.Template.Spec)
+	// Volume claim templates are immutable, set them only on creation
+	if statefulSet.CreationTimestamp.IsZero() {
+		statefulSet.Spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{
+			builder.redisDataClaim(builder.Instance.Spec.Master.Persistence, labels),
+		}
+	}
+
+	if err := controllerutil.SetControllerReference(builder.Instance, statefulSet, builder.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+	return nil
+}
+
+func (builder *RedisMasterStatefulSetBuilder) Outcome() Outcome {
+	if builder.isClusterMode() || builder.Instance.Spec.Master.Count < 1 {
+		return OutcomeRemove
+	}
+	return deployedIf(builder.isDeployedKind(metadata.RedisMasterComponent(), builder.Instance.Spec.Master.Kind, cachev1alpha1.KindStatefulSet))
+}
@@ -0,0 +1,110 @@
+package resources
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	metadata "github.com/avekrivoy/redis-operator/internal/metadata"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const maxMemoryDirective = "maxmemory"
+
+// memoryUnits are multipliers of memory units accepted in redis.conf
+var memoryUnits = map[string]int64{
+	"":   1,
+	"b":  1,
+	"k":  1000,
+	"kb": 1024,
+	"m":  1000 * 1000,
+	"mb": 1024 * 1024,
+	"g":  1000 * 1000 * 1000,
+	"gb": 1024 * 1024 * 1024,
+}
+
+// ParseMemory returns number of bytes of redis.conf memory value, e.g. '512mb'
+func ParseMemory(value string) (int64, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	unitStart := strings.IndexFunc(value, func(r rune) bool { return r < '0' || r > '9' })
+	if unitStart < 0 {
+		unitStart = len(value)
+	}
+	multiplier, ok := memoryUnits[value[unitStart:]]
+	if !ok || unitStart == 0 {
+		return 0, fmt.Errorf("invalid memory value %q", value)
+	}
+	number, err := strconv.ParseInt(value[:unitStart], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory value %q: %w", value, err)
+	}
+	return number * multiplier, nil
+}
+
+// ComponentResources returns compute resources of Redis container of the component
+func (builder *RedisResourceBuilder) ComponentResources(component string) corev1.ResourceRequirements {
+	spec := builder.Instance.Spec
+	switch component {
+	case metadata.RedisMasterComponent():
+		return spec.Master.Resources
+	case metadata.RedisReplicaComponent():
+		return spec.Replica.Resources
+	case metadata.RedisClusterComponent():
+		return spec.Cluster.Resources
+	}
+	return corev1.ResourceRequirements{}
+}
+
+// memoryLimit returns memory limit of Redis container of the component in bytes, 0 without limit
+func (builder *RedisResourceBuilder) memoryLimit(component string) int64 {
+	limit, ok := builder.ComponentResources(component).Limits[corev1.ResourceMemory]
+	if !ok {
+		return 0
+	}
+	return limit.Value()
+}
+
+// configValue returns value of the directive in config, directive names are case insensitive
+func configValue(config cachev1alpha1.RedisConfig, directive string) (string, bool) {
+	for name, value := range config {
+		if strings.EqualFold(name, directive) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// setDerivedMaxMemory sets maxmemory to the configured percentage of the
+// memory limit unless it is set explicitly, so Redis evicts keys before the
+// container is OOM-killed
+func (builder *RedisResourceBuilder) setDerivedMaxMemory(config cachev1alpha1.RedisConfig, component string) {
+	if _, ok := configValue(config, maxMemoryDirective); ok {
+		return
+	}
+	limit := builder.memoryLimit(component)
+	if limit == 0 {
+		return
+	}
+	percent := int64(builder.Instance.Spec.Common.GetMaxMemoryPercent())
+	config[maxMemoryDirective] = strconv.FormatInt(limit*percent/100, 10)
+}
+
+// MaxMemoryExceedingLimit returns messages about components whose maxmemory
+// set in config exceeds the memory limit of their Redis container
+func (builder *RedisResourceBuilder) MaxMemoryExceedingLimit() []string {
+	messages := []string{}
+	for _, component := range builder.ConfigComponents() {
+		limit := builder.memoryLimit(component)
+		value, ok := configValue(builder.RedisConfig(component), maxMemoryDirective)
+		if limit == 0 || !ok {
+			continue
+		}
+		maxMemory, err := ParseMemory(value)
+		if err != nil || maxMemory <= limit {
+			continue
+		}
+		messages = append(messages, fmt.Sprintf("maxmemory %s of %s exceeds memory limit of %d bytes", value, component, limit))
+	}
+	return messages
+}
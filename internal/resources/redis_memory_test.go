@@ -0,0 +1,87 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	"github.com/avekrivoy/redis-operator/internal/metadata"
+)
+
+var _ = Describe("Redis memory", func() {
+	var builder *RedisResourceBuilder
+
+	BeforeEach(func() {
+		instance := newTestInstance()
+		instance.Spec.Master.Resources = corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")},
+			Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+		}
+		builder = &RedisResourceBuilder{Instance: instance, Scheme: newTestScheme()}
+	})
+
+	DescribeTable("should parse redis.conf memory values",
+		func(value string, bytes int64) {
+			Expect(ParseMemory(value)).To(Equal(bytes))
+		},
+		Entry("bytes", "1024", int64(1024)),
+		Entry("kilobytes", "1k", int64(1000)),
+		Entry("kibibytes", "1KB", int64(1024)),
+		Entry("mebibytes", "512mb", int64(512*1024*1024)),
+		Entry("gibibytes", "2gb", int64(2*1024*1024*1024)),
+	)
+
+	It("should reject malformed memory values", func() {
+		_, err := ParseMemory("1.5gb")
+		Expect(err).To(HaveOccurred())
+		_, err = ParseMemory("mb")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should set container resources", func() {
+		container := updated(builder.RedisMasterDeployment()).(*appsv1.Deployment).Spec.Template.Spec.Containers[0]
+		Expect(container.Resources).To(Equal(builder.Instance.Spec.Master.Resources))
+	})
+
+	It("should derive maxmemory from memory limit", func() {
+		Expect(builder.RedisConfig(metadata.RedisMasterComponent())).To(
+			HaveKeyWithValue("maxmemory", "805306368"))
+		Expect(builder.RedisConfig(metadata.RedisReplicaComponent())).NotTo(HaveKey("maxmemory"))
+
+		builder.Instance.Spec.Common.MaxMemoryPercent = 50
+		Expect(builder.RedisConfig(metadata.RedisMasterComponent())).To(
+			HaveKeyWithValue("maxmemory", "536870912"))
+	})
+
+	It("should keep maxmemory set explicitly", func() {
+		builder.Instance.Spec.Master.Config = cachev1alpha1.RedisConfig{"maxmemory": "256mb"}
+		Expect(builder.RedisConfig(metadata.RedisMasterComponent())).To(
+			HaveKeyWithValue("maxmemory", "256mb"))
+		Expect(builder.MaxMemoryExceedingLimit()).To(BeEmpty())
+	})
+
+	It("should report maxmemory exceeding memory limit", func() {
+		builder.Instance.Spec.Common.Config = cachev1alpha1.RedisConfig{"MaxMemory": "2gb"}
+		Expect(builder.MaxMemoryExceedingLimit()).To(ConsistOf(ContainSubstring("redis-master")))
+	})
+})
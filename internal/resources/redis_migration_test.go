@@ -0,0 +1,79 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	"github.com/avekrivoy/redis-operator/internal/metadata"
+)
+
+var _ = Describe("Redis workload migration", func() {
+	var builder *RedisResourceBuilder
+
+	BeforeEach(func() {
+		instance := newTestInstance()
+		instance.Spec.Master.Kind = cachev1alpha1.KindStatefulSet
+		builder = &RedisResourceBuilder{Instance: instance, Scheme: newTestScheme()}
+	})
+
+	serviceSelector := func() map[string]string {
+		return updated(builder.RedisMasterService()).(*corev1.Service).Spec.Selector
+	}
+
+	setPhase := func(phase cachev1alpha1.RedisMigrationPhase) {
+		builder.Instance.Status.Migrations = []cachev1alpha1.RedisMigrationStatus{{
+			Component: metadata.RedisMasterComponent(),
+			From:      cachev1alpha1.KindDeployment,
+			To:        cachev1alpha1.KindStatefulSet,
+			Phase:     phase,
+		}}
+	}
+
+	It("should keep source workload and service until target is in sync", func() {
+		setPhase(cachev1alpha1.MigrationPhaseSyncing)
+		Expect(builder.RedisMasterDeployment().Outcome()).To(Equal(OutcomeDeploy))
+		Expect(builder.RedisMasterStatefulSet().Outcome()).To(Equal(OutcomeDeploy))
+		Expect(serviceSelector()).To(HaveKeyWithValue(metadata.WorkloadKindLabel, cachev1alpha1.KindDeployment))
+	})
+
+	It("should switch service to target workload", func() {
+		setPhase(cachev1alpha1.MigrationPhaseSwitchingService)
+		Expect(serviceSelector()).To(HaveKeyWithValue(metadata.WorkloadKindLabel, cachev1alpha1.KindStatefulSet))
+	})
+
+	It("should keep target workload serving when kind is changed back", func() {
+		setPhase(cachev1alpha1.MigrationPhaseSwitchingService)
+		builder.Instance.Spec.Master.Kind = cachev1alpha1.KindDeployment
+		Expect(builder.RedisMasterStatefulSet().Outcome()).To(Equal(OutcomeDeploy))
+		Expect(serviceSelector()).To(HaveKeyWithValue(metadata.WorkloadKindLabel, cachev1alpha1.KindStatefulSet))
+
+		setPhase(cachev1alpha1.MigrationPhaseRemovingSource)
+		Expect(builder.RedisMasterDeployment().Outcome()).To(Equal(OutcomeRemove))
+		Expect(builder.RedisMasterStatefulSet().Outcome()).To(Equal(OutcomeDeploy))
+	})
+
+	It("should stop rendering source workload once it is being removed", func() {
+		setPhase(cachev1alpha1.MigrationPhaseRemovingSource)
+		Expect(builder.RedisMasterDeployment().Outcome()).To(Equal(OutcomeRemove))
+		Expect(builder.RedisMasterStatefulSet().Outcome()).To(Equal(OutcomeDeploy))
+	})
+})
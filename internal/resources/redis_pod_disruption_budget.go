@@ -0,0 +1,116 @@
+package resources
+
+import (
+	"fmt"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	metadata "github.com/avekrivoy/redis-operator/internal/metadata"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// RedisPodDisruptionBudgetBuilder renders PodDisruptionBudget of pods of a
+// Redis component, both workload kinds of the component are covered. The
+// budget of the default component spans master and replica pods: in
+// replication mode there is a single master, which budgets of components do
+// not protect from being evicted together with its replicas. Budgets of
+// components are rendered only without it, as the eviction API refuses to
+// evict pods selected by more than one budget.
+type RedisPodDisruptionBudgetBuilder struct {
+	*RedisResourceBuilder
+	Component string
+}
+
+func (builder *RedisResourceBuilder) RedisMasterPodDisruptionBudget() *RedisPodDisruptionBudgetBuilder {
+	return &RedisPodDisruptionBudgetBuilder{builder, metadata.RedisMasterComponent()}
+}
+
+func (builder *RedisResourceBuilder) RedisReplicaPodDisruptionBudget() *RedisPodDisruptionBudgetBuilder {
+	return &RedisPodDisruptionBudgetBuilder{builder, metadata.RedisReplicaComponent()}
+}
+
+func (builder *RedisResourceBuilder) RedisPodDisruptionBudget() *RedisPodDisruptionBudgetBuilder {
+	return &RedisPodDisruptionBudgetBuilder{builder, metadata.DefaultComponent}
+}
+
+func (builder *RedisPodDisruptionBudgetBuilder) Build() (client.Object, error) {
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      metadata.RedisServiceName(builder.Instance.Name, builder.Component),
+			Namespace: builder.Instance.Namespace,
+		},
+	}, nil
+}
+
+func (builder *RedisPodDisruptionBudgetBuilder) Update(object client.Object) error {
+	pdbLabels := metadata.Label{
+		"app.kubernetes.io/component": builder.Component,
+	}
+
+	pdb := object.(*policyv1.PodDisruptionBudget)
+	pdb.ObjectMeta.Labels = metadata.ResourceLabels(builder.Instance.Name, pdbLabels)
+	pdb.Spec.Selector = builder.selector()
+
+	spec := builder.spec()
+	pdb.Spec.MinAvailable = spec.MinAvailable
+	pdb.Spec.MaxUnavailable = spec.MaxUnavailable
+	if spec.MinAvailable == nil && spec.MaxUnavailable == nil {
+		maxUnavailable := intstr.FromInt32(1)
+		pdb.Spec.MaxUnavailable = &maxUnavailable
+	}
+
+	if err := controllerutil.SetControllerReference(builder.Instance, pdb, builder.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+	return nil
+}
+
+// Outcome skips PodDisruptionBudget of a single pod, which would block node
+// drains, and budgets of components overlapping the budget of master and replicas
+func (builder *RedisPodDisruptionBudgetBuilder) Outcome() Outcome {
+	if builder.Component != metadata.DefaultComponent && builder.Instance.Spec.Common.PodDisruptionBudget.IsEnabled() {
+		return OutcomeRemove
+	}
+	return deployedIf(!builder.isClusterMode() && builder.spec().IsEnabled() && builder.count() > 1)
+}
+
+// selector selects pods of the component, or master and replica pods but not
+// Sentinel pods for the default component
+func (builder *RedisPodDisruptionBudgetBuilder) selector() *metav1.LabelSelector {
+	if builder.Component != metadata.DefaultComponent {
+		return &metav1.LabelSelector{
+			MatchLabels: metadata.LabelSelector(builder.Instance.Name, builder.Component),
+		}
+	}
+	return &metav1.LabelSelector{
+		MatchLabels: metadata.Label{"app.kubernetes.io/name": builder.Instance.Name},
+		MatchExpressions: []metav1.LabelSelectorRequirement{{
+			Key:      "app.kubernetes.io/component",
+			Operator: metav1.LabelSelectorOpIn,
+			Values:   []string{metadata.RedisMasterComponent(), metadata.RedisReplicaComponent()},
+		}},
+	}
+}
+
+func (builder *RedisPodDisruptionBudgetBuilder) spec() *cachev1alpha1.RedisPodDisruptionBudgetSpec {
+	switch builder.Component {
+	case metadata.RedisMasterComponent():
+		return &builder.Instance.Spec.Master.PodDisruptionBudget
+	case metadata.RedisReplicaComponent():
+		return &builder.Instance.Spec.Replica.PodDisruptionBudget
+	}
+	return &builder.Instance.Spec.Common.PodDisruptionBudget
+}
+
+func (builder *RedisPodDisruptionBudgetBuilder) count() int32 {
+	switch builder.Component {
+	case metadata.RedisMasterComponent():
+		return builder.Instance.Spec.Master.Count
+	case metadata.RedisReplicaComponent():
+		return builder.Instance.Spec.Replica.Count
+	}
+	return builder.Instance.Spec.Master.Count + builder.Instance.Spec.Replica.Count
+}
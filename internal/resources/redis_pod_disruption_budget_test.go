@@ -0,0 +1,139 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+)
+
+var _ = Describe("Redis PodDisruptionBudget", func() {
+	var builder *RedisResourceBuilder
+
+	BeforeEach(func() {
+		instance := newTestInstance()
+		instance.Spec.Replica.Count = 3
+		builder = &RedisResourceBuilder{Instance: instance, Scheme: newTestScheme()}
+	})
+
+	It("should allow one replica to be evicted by default", func() {
+		builder.Instance.Spec.Common.PodDisruptionBudget.Enabled = new(bool)
+		pdbBuilder := builder.RedisReplicaPodDisruptionBudget()
+		Expect(pdbBuilder.Outcome()).To(Equal(OutcomeDeploy))
+
+		pdb := updated(pdbBuilder).(*policyv1.PodDisruptionBudget)
+		Expect(pdb.Name).To(Equal("test-redis-redis-replica"))
+		Expect(pdb.Spec.Selector.MatchLabels).To(HaveKeyWithValue("app.kubernetes.io/component", "redis-replica"))
+		Expect(*pdb.Spec.MaxUnavailable).To(Equal(intstr.FromInt32(1)))
+		Expect(pdb.Spec.MinAvailable).To(BeNil())
+		Expect(pdb.OwnerReferences).To(HaveLen(1))
+	})
+
+	It("should use minAvailable of the spec", func() {
+		builder.Instance.Spec.Common.PodDisruptionBudget.Enabled = new(bool)
+		minAvailable := intstr.FromString("50%")
+		builder.Instance.Spec.Replica.PodDisruptionBudget.MinAvailable = &minAvailable
+		pdb := updated(builder.RedisReplicaPodDisruptionBudget()).(*policyv1.PodDisruptionBudget)
+		Expect(*pdb.Spec.MinAvailable).To(Equal(minAvailable))
+		Expect(pdb.Spec.MaxUnavailable).To(BeNil())
+	})
+
+	It("should skip single pod and disabled budgets", func() {
+		builder.Instance.Spec.Common.PodDisruptionBudget.Enabled = new(bool)
+		Expect(builder.RedisMasterPodDisruptionBudget().Outcome()).To(Equal(OutcomeRemove))
+
+		builder.Instance.Spec.Replica.PodDisruptionBudget.Enabled = new(bool)
+		Expect(builder.RedisReplicaPodDisruptionBudget().Outcome()).To(Equal(OutcomeRemove))
+	})
+
+	It("should cover master together with replicas", func() {
+		pdbBuilder := builder.RedisPodDisruptionBudget()
+		Expect(pdbBuilder.Outcome()).To(Equal(OutcomeDeploy))
+
+		pdb := updated(pdbBuilder).(*policyv1.PodDisruptionBudget)
+		Expect(pdb.Name).To(Equal("test-redis-redis"))
+		Expect(pdb.Spec.Selector.MatchLabels).To(Equal(map[string]string{"app.kubernetes.io/name": "test-redis"}))
+		Expect(pdb.Spec.Selector.MatchExpressions).To(ConsistOf(metav1.LabelSelectorRequirement{
+			Key:      "app.kubernetes.io/component",
+			Operator: metav1.LabelSelectorOpIn,
+			Values:   []string{"redis-master", "redis-replica"},
+		}))
+		Expect(*pdb.Spec.MaxUnavailable).To(Equal(intstr.FromInt32(1)))
+
+		builder.Instance.Spec.Replica.Count = 0
+		Expect(builder.RedisPodDisruptionBudget().Outcome()).To(Equal(OutcomeRemove))
+	})
+
+	It("should never select a pod by two budgets", func() {
+		builder.Instance.Spec.Master.Count = 2
+		for _, commonEnabled := range []bool{true, false} {
+			builder.Instance.Spec.Common.PodDisruptionBudget.Enabled = &commonEnabled
+
+			var selectors []labels.Selector
+			for _, pdbBuilder := range []ResourceBuilder{
+				builder.RedisPodDisruptionBudget(),
+				builder.RedisMasterPodDisruptionBudget(),
+				builder.RedisReplicaPodDisruptionBudget(),
+			} {
+				if pdbBuilder.Outcome() != OutcomeDeploy {
+					continue
+				}
+				selector, err := metav1.LabelSelectorAsSelector(updated(pdbBuilder).(*policyv1.PodDisruptionBudget).Spec.Selector)
+				Expect(err).NotTo(HaveOccurred())
+				selectors = append(selectors, selector)
+			}
+			Expect(selectors).NotTo(BeEmpty())
+
+			for _, workload := range []client.Object{
+				updated(builder.RedisMasterDeployment()),
+				updated(builder.RedisMasterStatefulSet()),
+				updated(builder.RedisReplicaDeployment()),
+				updated(builder.RedisReplicaStatefulSet()),
+			} {
+				var podLabels labels.Set
+				switch workload := workload.(type) {
+				case *appsv1.Deployment:
+					podLabels = workload.Spec.Template.Labels
+				case *appsv1.StatefulSet:
+					podLabels = workload.Spec.Template.Labels
+				}
+				matches := 0
+				for _, selector := range selectors {
+					if selector.Matches(podLabels) {
+						matches++
+					}
+				}
+				Expect(matches).To(Equal(1), "pods labeled %v", podLabels)
+			}
+		}
+	})
+
+	It("should skip budgets in cluster mode", func() {
+		builder.Instance.Spec.Mode = cachev1alpha1.ModeCluster
+		Expect(builder.RedisReplicaPodDisruptionBudget().Outcome()).To(Equal(OutcomeRemove))
+		Expect(builder.RedisPodDisruptionBudget().Outcome()).To(Equal(OutcomeRemove))
+	})
+})
@@ -0,0 +1,310 @@
+package resources
+
+import (
+	"fmt"
+	"time"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	metadata "github.com/avekrivoy/redis-operator/internal/metadata"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	redisContainerName = "redis"
+	redisPort          = 6379
+	redisDataVolume    = "redis-data"
+	// Bitnami image keeps RDB and AOF files in this directory
+	redisDataPath = "/bitnami/redis/data"
+	// User of Bitnami image, data files have to be writable by Redis
+	redisUser = 1001
+)
+
+func (builder *RedisResourceBuilder) redisImage() string {
+	return fmt.Sprintf("%s:%s", builder.Instance.Spec.Common.Image.ImageRepository, builder.Instance.Spec.Common.Image.ImageTag)
+}
+
+func (builder *RedisResourceBuilder) redisAuthSecretName() string {
+	return AuthSecretName(builder.Instance)
+}
+
+// AuthSecretName returns name of the secret with REDIS_PASSWORD used by Redis pods
+func AuthSecretName(instance *cachev1alpha1.Redis) string {
+	// Check if existing auth secret was specified
+	if instance.Spec.Common.Auth.ExistingSecret == "" {
+		return metadata.RedisAuthSecretName(instance.Name)
+	}
+	return instance.Spec.Common.Auth.ExistingSecret
+}
+
+// podLabels returns labels of Redis pods run by workload of given kind
+func (builder *RedisResourceBuilder) podLabels(component string, kind string) metadata.Label {
+	podLabels := metadata.Label{
+		"app.kubernetes.io/component": component,
+		metadata.WorkloadKindLabel:    kind,
+	}
+	return metadata.ResourceLabels(builder.Instance.Name, podLabels)
+}
+
+// servingKind returns workload kind whose pods receive traffic of component Service.
+// During migration traffic stays on the source workload until target pods are in sync.
+func (builder *RedisResourceBuilder) servingKind(component string, specKind string) string {
+	migration := builder.Instance.Status.ActiveMigration(component)
+	if migration == nil {
+		return specKind
+	}
+	if migration.Phase == cachev1alpha1.MigrationPhaseCreatingTarget || migration.Phase == cachev1alpha1.MigrationPhaseSyncing {
+		return migration.From
+	}
+	return migration.To
+}
+
+// serviceSelector returns selector of Redis master or replica Service.
+// In Sentinel mode roles move between pods, so pods are selected by the role label
+// the operator keeps in sync with the master elected by Sentinel.
+func (builder *RedisResourceBuilder) serviceSelector(component string, specKind string, role string) metadata.Label {
+	if builder.Instance.Spec.Sentinel.Enabled {
+		return metadata.RoleLabelSelector(builder.Instance.Name, role)
+	}
+	return metadata.WorkloadLabelSelector(builder.Instance.Name, component, builder.servingKind(component, specKind))
+}
+
+// isClusterMode checks if Redis runs in cluster mode, where master, replica
+// and Sentinel components are replaced by cluster shards
+func (builder *RedisResourceBuilder) isClusterMode() bool {
+	return builder.Instance.Spec.Mode == cachev1alpha1.ModeCluster
+}
+
+// isMigrationSource checks if workload of given kind must be kept while migration is in progress
+func (builder *RedisResourceBuilder) isMigrationSource(component string, kind string) bool {
+	migration := builder.Instance.Status.ActiveMigration(component)
+	return migration != nil && migration.From == kind && migration.Phase != cachev1alpha1.MigrationPhaseRemovingSource
+}
+
+// isDeployedKind checks if workload of given kind is deployed for component.
+// While migration is in progress workloads follow the migration instead of
+// the kind of spec, so a kind changed back once target pods serve traffic does
+// not prune them.
+func (builder *RedisResourceBuilder) isDeployedKind(component string, specKind string, kind string) bool {
+	migration := builder.Instance.Status.ActiveMigration(component)
+	if migration == nil {
+		return kind == specKind
+	}
+	return kind == migration.To || builder.isMigrationSource(component, kind)
+}
+
+// isAuthEnabled checks if Redis pods require password
+func (builder *RedisResourceBuilder) isAuthEnabled() bool {
+	return builder.Instance.Spec.Common.Auth.IsEnabled()
+}
+
+// setPasswordRotationAnnotation annotates replica pod template with the time of
+// the password rotation replicas have to be restarted for. The time of the
+// rotation in progress becomes the last rotation time on completion, so pods
+// are restarted once per rotation.
+func (builder *RedisResourceBuilder) setPasswordRotationAnnotation(template *corev1.PodTemplateSpec) {
+	var rotationTime *metav1.Time
+	if auth := builder.Instance.Status.Auth; auth != nil {
+		rotationTime = auth.LastRotationTime
+		if auth.Rotation != nil && auth.Rotation.Phase != cachev1alpha1.PasswordRotationPhaseAddingPassword {
+			rotationTime = &auth.Rotation.StartTime
+		}
+	}
+	if rotationTime == nil {
+		delete(template.Annotations, metadata.PasswordRotationAnnotation)
+		return
+	}
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[metadata.PasswordRotationAnnotation] = rotationTime.UTC().Format(time.RFC3339)
+}
+
+// redisContainer returns Redis container of the component reading REDIS_PASSWORD
+// from the auth secret. Without authentication Bitnami image has to be allowed
+// to start without password.
+func (builder *RedisResourceBuilder) redisContainer(component string, env []corev1.EnvVar) corev1.Container {
+	container := corev1.Container{
+		Image:           builder.redisImage(),
+		ImagePullPolicy: corev1.PullPolicy(builder.Instance.Spec.Common.Image.ImagePullPolicy),
+		Name:            redisContainerName,
+		Ports: []corev1.ContainerPort{{
+			ContainerPort: redisPort,
+			Name:          "redis",
+		}},
+		Env:       env,
+		Resources: builder.ComponentResources(component),
+	}
+
+	if builder.isAuthEnabled() {
+		container.EnvFrom = []corev1.EnvFromSource{{
+			SecretRef: &corev1.SecretEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: builder.redisAuthSecretName(),
+				},
+			},
+		}}
+	} else {
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name:  "ALLOW_EMPTY_PASSWORD",
+			Value: "yes",
+		})
+	}
+	return container
+}
+
+// redisMasterPodSpec returns pod spec shared by master Deployment and StatefulSet
+func (builder *RedisResourceBuilder) redisMasterPodSpec() corev1.PodSpec {
+	env := []corev1.EnvVar{
+		{
+			Name:  "REDIS_REPLICATION_MODE",
+			Value: "master",
+		},
+	}
+	podSpec := corev1.PodSpec{
+		ImagePullSecrets: builder.Instance.Spec.Common.Image.ImagePullSecrets,
+		Containers: []corev1.Container{
+			builder.redisContainer(metadata.RedisMasterComponent(), env),
+		},
+	}
+	builder.mountConfig(&podSpec, metadata.RedisMasterComponent())
+	builder.mountACLFile(&podSpec)
+	builder.enableTLS(&podSpec)
+	builder.setProbes(&podSpec)
+	builder.setRestore(&podSpec)
+	builder.setScheduling(&podSpec, builder.Instance.Spec.Master.RedisSchedulingSpec, metadata.RedisMasterComponent())
+	return podSpec
+}
+
+// replicationEnv returns environment starting Bitnami image as a replica of
+// the master at host and port, authenticated with the password in
+// passwordSecret unless it is empty
+func replicationEnv(host string, port int32, passwordSecret string) []corev1.EnvVar {
+	env := []corev1.EnvVar{
+		{
+			Name:  "REDIS_REPLICATION_MODE",
+			Value: "slave",
+		},
+		{
+			Name:  "REDIS_MASTER_HOST",
+			Value: host,
+		},
+	}
+	if passwordSecret != "" {
+		env = append(env, corev1.EnvVar{
+			Name: "REDIS_MASTER_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: passwordSecret,
+					},
+					Key: AuthPasswordKey,
+				},
+			},
+		})
+	}
+	return append(env, corev1.EnvVar{
+		Name:  "REDIS_MASTER_PORT_NUMBER",
+		Value: fmt.Sprint(port),
+	})
+}
+
+// redisReplicaPodSpec returns pod spec shared by replica Deployment and StatefulSet
+func (builder *RedisResourceBuilder) redisReplicaPodSpec() corev1.PodSpec {
+	passwordSecret := ""
+	if builder.isAuthEnabled() {
+		passwordSecret = builder.redisAuthSecretName()
+	}
+	env := replicationEnv(metadata.RedisServiceName(builder.Instance.Name, metadata.RedisMasterComponent()), redisPort, passwordSecret)
+
+	podSpec := corev1.PodSpec{
+		ImagePullSecrets: builder.Instance.Spec.Common.Image.ImagePullSecrets,
+		Containers: []corev1.Container{
+			builder.redisContainer(metadata.RedisReplicaComponent(), env),
+		},
+	}
+	builder.mountConfig(&podSpec, metadata.RedisReplicaComponent())
+	builder.mountACLFile(&podSpec)
+	builder.enableTLS(&podSpec)
+	builder.setProbes(&podSpec)
+	builder.setScheduling(&podSpec, builder.Instance.Spec.Replica.RedisSchedulingSpec, metadata.RedisReplicaComponent())
+	return podSpec
+}
+
+// redisClusterPodSpec returns pod spec of Redis Cluster nodes. Every node starts
+// as an empty master, the operator joins nodes and assigns slots and replicas.
+func (builder *RedisResourceBuilder) redisClusterPodSpec() corev1.PodSpec {
+	// nodes.conf is kept on the data volume to preserve node ID across restarts
+	clusterFlags := fmt.Sprintf("--cluster-enabled yes --cluster-config-file %s/nodes.conf --cluster-node-timeout %d",
+		redisDataPath, builder.Instance.Spec.Cluster.NodeTimeout)
+
+	podSpec := corev1.PodSpec{
+		ImagePullSecrets: builder.Instance.Spec.Common.Image.ImagePullSecrets,
+		Containers: []corev1.Container{
+			builder.redisContainer(metadata.RedisClusterComponent(), []corev1.EnvVar{
+				{
+					Name:  "REDIS_REPLICATION_MODE",
+					Value: "master",
+				},
+				{
+					Name:  "REDIS_EXTRA_FLAGS",
+					Value: clusterFlags,
+				},
+			}),
+		},
+	}
+	builder.mountConfig(&podSpec, metadata.RedisClusterComponent())
+	builder.mountACLFile(&podSpec)
+	builder.enableTLS(&podSpec)
+	builder.setProbes(&podSpec)
+	builder.setScheduling(&podSpec, builder.Instance.Spec.Cluster.RedisSchedulingSpec, metadata.RedisClusterComponent())
+	return podSpec
+}
+
+// setDataFSGroup gives the group of Redis user access to the data volume.
+// Claimed volumes are usually owned by root, Bitnami image runs as non-root user.
+func setDataFSGroup(podSpec *corev1.PodSpec) {
+	if podSpec.SecurityContext == nil {
+		podSpec.SecurityContext = &corev1.PodSecurityContext{}
+	}
+	podSpec.SecurityContext.FSGroup = ptr.To[int64](redisUser)
+	podSpec.SecurityContext.FSGroupChangePolicy = ptr.To(corev1.FSGroupChangeOnRootMismatch)
+}
+
+// mountRedisData mounts data volume claimed by StatefulSet into Redis container
+func mountRedisData(podSpec *corev1.PodSpec) {
+	setDataFSGroup(podSpec)
+
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name != redisContainerName {
+			continue
+		}
+		podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, corev1.VolumeMount{
+			Name:      redisDataVolume,
+			MountPath: redisDataPath,
+		})
+	}
+}
+
+// redisDataClaim returns PVC template for Redis data directory
+func (builder *RedisResourceBuilder) redisDataClaim(persistence cachev1alpha1.RedisPersistenceSpec, labels metadata.Label) corev1.PersistentVolumeClaim {
+	claim := corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   redisDataVolume,
+			Labels: labels,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: persistence.AccessModes,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: persistence.Size,
+				},
+			},
+		},
+	}
+	if builder.Instance.Spec.Common.StorageClass != "" {
+		claim.Spec.StorageClassName = &builder.Instance.Spec.Common.StorageClass
+	}
+	return claim
+}
@@ -0,0 +1,126 @@
+package resources
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	redisAuthVolume = "redis-auth"
+	// Auth secret is mounted for probes, so they use the current password after rotation
+	redisAuthPath = "/opt/bitnami/redis/secrets"
+)
+
+// Probe thresholds used for values not set in the spec
+var (
+	defaultLivenessProbe  = cachev1alpha1.RedisProbeSpec{PeriodSeconds: 10, TimeoutSeconds: 5, FailureThreshold: 5}
+	defaultReadinessProbe = cachev1alpha1.RedisProbeSpec{PeriodSeconds: 5, TimeoutSeconds: 3, FailureThreshold: 3}
+	defaultStartupProbe   = cachev1alpha1.RedisProbeSpec{PeriodSeconds: 5, TimeoutSeconds: 5, FailureThreshold: 60}
+)
+
+// redisCLI returns redis-cli command connecting to Redis in the same container
+func (builder *RedisResourceBuilder) redisCLI() string {
+	cli := []string{}
+	if builder.isAuthEnabled() {
+		cli = append(cli, fmt.Sprintf(`REDISCLI_AUTH="$(cat %s)"`, path.Join(redisAuthPath, AuthPasswordKey)))
+	}
+	cli = append(cli, "redis-cli", "-h", "localhost", "-p", fmt.Sprint(redisPort))
+	if builder.isTLSEnabled() {
+		cli = append(cli, "--tls",
+			"--cacert", tlsFile(TLSCAKey),
+			"--cert", tlsFile(corev1.TLSCertKey),
+			"--key", tlsFile(corev1.TLSPrivateKeyKey))
+	}
+	return strings.Join(cli, " ")
+}
+
+// livenessCommand succeeds while Redis answers PING, also while it is loading
+// data or its master is down, which restart would not fix
+func (builder *RedisResourceBuilder) livenessCommand() string {
+	return fmt.Sprintf(`response="$(%s ping)"
+case "$response" in
+  PONG|LOADING*|MASTERDOWN*) exit 0 ;;
+esac
+echo "$response"
+exit 1`, builder.redisCLI())
+}
+
+// readinessCommand succeeds when Redis answers PING with the dataset loaded
+// and, if it is a replica, its link to master is up
+func (builder *RedisResourceBuilder) readinessCommand() string {
+	cli := builder.redisCLI()
+	return fmt.Sprintf(`response="$(%s ping)"
+if [ "$response" != "PONG" ]; then
+  echo "$response"
+  exit 1
+fi
+replication="$(%s info replication)" || exit 1
+case "$replication" in
+  *role:slave*)
+    echo "$replication" | grep -q "^master_link_status:up" || { echo "master link is down"; exit 1; } ;;
+esac`, cli, cli)
+}
+
+// startupCommand succeeds once Redis answers PING, i.e. the dataset is loaded
+func (builder *RedisResourceBuilder) startupCommand() string {
+	return fmt.Sprintf(`response="$(%s ping)"
+[ "$response" = "PONG" ] || { echo "$response"; exit 1; }`, builder.redisCLI())
+}
+
+// redisProbe returns probe running command with thresholds of spec falling back to defaults
+func redisProbe(command string, spec cachev1alpha1.RedisProbeSpec, defaults cachev1alpha1.RedisProbeSpec) *corev1.Probe {
+	valueOrDefault := func(value int32, defaultValue int32) int32 {
+		if value == 0 {
+			return defaultValue
+		}
+		return value
+	}
+	return &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			Exec: &corev1.ExecAction{Command: []string{"sh", "-c", command}},
+		},
+		InitialDelaySeconds: valueOrDefault(spec.InitialDelaySeconds, defaults.InitialDelaySeconds),
+		PeriodSeconds:       valueOrDefault(spec.PeriodSeconds, defaults.PeriodSeconds),
+		TimeoutSeconds:      valueOrDefault(spec.TimeoutSeconds, defaults.TimeoutSeconds),
+		FailureThreshold:    valueOrDefault(spec.FailureThreshold, defaults.FailureThreshold),
+		SuccessThreshold:    1,
+	}
+}
+
+// setProbes adds liveness, readiness and startup probes to Redis container.
+// Probes are shared by master, replica and cluster pods, readiness checks the
+// role at runtime, as roles move between pods with Sentinel and in cluster mode.
+func (builder *RedisResourceBuilder) setProbes(podSpec *corev1.PodSpec) {
+	probes := builder.Instance.Spec.Common.Probes
+	if builder.isAuthEnabled() {
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: redisAuthVolume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: builder.redisAuthSecretName(),
+					Items:      []corev1.KeyToPath{{Key: AuthPasswordKey, Path: AuthPasswordKey}},
+				},
+			},
+		})
+	}
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		if container.Name != redisContainerName {
+			continue
+		}
+		if builder.isAuthEnabled() {
+			container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+				Name:      redisAuthVolume,
+				MountPath: redisAuthPath,
+				ReadOnly:  true,
+			})
+		}
+		container.LivenessProbe = redisProbe(builder.livenessCommand(), probes.Liveness, defaultLivenessProbe)
+		container.ReadinessProbe = redisProbe(builder.readinessCommand(), probes.Readiness, defaultReadinessProbe)
+		container.StartupProbe = redisProbe(builder.startupCommand(), probes.Startup, defaultStartupProbe)
+	}
+}
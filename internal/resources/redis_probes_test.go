@@ -0,0 +1,92 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+)
+
+var _ = Describe("Redis probes", func() {
+	var builder *RedisResourceBuilder
+
+	BeforeEach(func() {
+		builder = &RedisResourceBuilder{Instance: newTestInstance(), Scheme: newTestScheme()}
+	})
+
+	redisPodSpec := func(workloadBuilder ResourceBuilder) corev1.PodSpec {
+		switch workload := updated(workloadBuilder).(type) {
+		case *appsv1.Deployment:
+			return workload.Spec.Template.Spec
+		case *appsv1.StatefulSet:
+			return workload.Spec.Template.Spec
+		}
+		Fail("unexpected workload")
+		return corev1.PodSpec{}
+	}
+
+	It("should probe every Redis workload", func() {
+		builder.Instance.Spec.Replica.Count = 1
+		for _, workloadBuilder := range []ResourceBuilder{
+			builder.RedisMasterDeployment(),
+			builder.RedisMasterStatefulSet(),
+			builder.RedisReplicaDeployment(),
+			builder.RedisReplicaStatefulSet(),
+			builder.RedisClusterShard(0),
+		} {
+			container := redisPodSpec(workloadBuilder).Containers[0]
+			Expect(container.LivenessProbe).NotTo(BeNil())
+			Expect(container.ReadinessProbe).NotTo(BeNil())
+			Expect(container.StartupProbe).NotTo(BeNil())
+		}
+	})
+
+	It("should authenticate with the mounted password", func() {
+		podSpec := redisPodSpec(builder.RedisReplicaDeployment())
+		Expect(podSpec.Volumes).To(ContainElement(HaveField("Secret.SecretName", "test-redis-auth-secret")))
+		Expect(podSpec.Containers[0].VolumeMounts).To(ContainElement(HaveField("MountPath", "/opt/bitnami/redis/secrets")))
+
+		readiness := podSpec.Containers[0].ReadinessProbe.Exec.Command
+		Expect(readiness[2]).To(ContainSubstring(`REDISCLI_AUTH="$(cat /opt/bitnami/redis/secrets/REDIS_PASSWORD)" redis-cli`))
+		Expect(readiness[2]).To(ContainSubstring("master_link_status:up"))
+	})
+
+	It("should probe without password when auth is disabled", func() {
+		builder.Instance.Spec.Common.Auth.Enabled = new(bool)
+		podSpec := redisPodSpec(builder.RedisMasterDeployment())
+		Expect(podSpec.Containers[0].VolumeMounts).NotTo(ContainElement(HaveField("Name", "redis-auth")))
+		Expect(podSpec.Containers[0].LivenessProbe.Exec.Command[2]).NotTo(ContainSubstring("REDISCLI_AUTH"))
+	})
+
+	It("should probe over TLS", func() {
+		builder.Instance.Spec.Common.TLS.Enabled = true
+		command := redisPodSpec(builder.RedisMasterDeployment()).Containers[0].StartupProbe.Exec.Command[2]
+		Expect(command).To(ContainSubstring("--tls --cacert /opt/bitnami/redis/certs/ca.crt"))
+	})
+
+	It("should use thresholds of the spec", func() {
+		builder.Instance.Spec.Common.Probes.Startup = cachev1alpha1.RedisProbeSpec{FailureThreshold: 120}
+		startup := redisPodSpec(builder.RedisMasterDeployment()).Containers[0].StartupProbe
+		Expect(startup.FailureThreshold).To(Equal(int32(120)))
+		Expect(startup.PeriodSeconds).To(Equal(int32(5)))
+	})
+})
@@ -41,7 +41,7 @@ func (builder *RedisReplicaServiceBuilder) Update(object client.Object) error {
 
 	svc.ObjectMeta.Labels = svcLabels
 	svc.Spec = corev1.ServiceSpec{
-		Selector: metadata.LabelSelector(builder.Instance.Name, component),
+		Selector: builder.serviceSelector(component, builder.Instance.Spec.Replica.Kind, metadata.RoleReplica),
 		Ports: []corev1.ServicePort{
 			{
 				Port:     6379,
@@ -58,6 +58,6 @@ func (builder *RedisReplicaServiceBuilder) Update(object client.Object) error {
 	return nil
 }
 
-func (builder *RedisReplicaServiceBuilder) IsDeployed() bool {
-	return builder.Instance.Spec.Replica.Count > 0
+func (builder *RedisReplicaServiceBuilder) Outcome() Outcome {
+	return deployedIf(!builder.isClusterMode() && builder.Instance.Spec.Replica.Count > 0)
 }
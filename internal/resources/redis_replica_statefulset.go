@@ -0,0 +1,74 @@
+package resources
+
+import (
+	"fmt"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	metadata "github.com/avekrivoy/redis-operator/internal/metadata"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+type RedisReplicaStatefulSetBuilder struct {
+	*RedisResourceBuilder
+}
+
+func (builder *RedisResourceBuilder) RedisReplicaStatefulSet() *RedisReplicaStatefulSetBuilder {
+	return &RedisReplicaStatefulSetBuilder{builder}
+}
+
+func (builder *RedisReplicaStatefulSetBuilder) Build() (client.Object, error) {
+	component := metadata.RedisReplicaComponent()
+	statefulSetName := fmt.Sprintf("%s-%s", builder.Instance.Name, component)
+
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      statefulSetName,
+			Namespace: builder.Instance.Namespace,
+		},
+	}, nil
+}
+
+func (builder *RedisReplicaStatefulSetBuilder) Update(object client.Object) error {
+	component := metadata.RedisReplicaComponent()
+
+	statefulSetLabels := metadata.Label{
+		"app.kubernetes.io/component": component,
+	}
+
+	labels := metadata.ResourceLabels(builder.Instance.Name, statefulSetLabels)
+
+	statefulSet := object.(*appsv1.StatefulSet)
+	statefulSet.ObjectMeta.Labels = labels
+	statefulSet.Spec.Replicas = builder.replicaCount()
+	statefulSet.Spec.ServiceName = metadata.RedisServiceName(builder.Instance.Name, component)
+	statefulSet.Spec.Template.ObjectMeta.Labels = builder.podLabels(component, cachev1alpha1.KindStatefulSet)
+	statefulSet.Spec.Selector = &metav1.LabelSelector{
+		MatchLabels: metadata.LabelSelector(builder.Instance.Name, component),
+	}
+	statefulSet.Spec.Template.Spec = builder.redisReplicaPodSpec()
+	builder.setConfigChecksumAnnotation(&statefulSet.Spec.Template, component)
+	builder.setPasswordRotationAnnotation(&statefulSet.Spec.Template)
+	mountRedisData(&statefulSet.Spec.Template.Spec)
+	// Volume claim templates are immutable, set them only on creation
+	if statefulSet.CreationTimestamp.IsZero() {
+		statefulSet.Spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{
+			builder.redisDataClaim(builder.Instance.Spec.Replica.Persistence, labels),
+		}
+	}
+
+	if err := controllerutil.SetControllerReference(builder.Instance, statefulSet, builder.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+	return nil
+}
+
+func (builder *RedisReplicaStatefulSetBuilder) Outcome() Outcome {
+	if builder.isClusterMode() || builder.Instance.Spec.Replica.Count < 1 {
+		return OutcomeRemove
+	}
+	return deployedIf(builder.isDeployedKind(metadata.RedisReplicaComponent(), builder.Instance.Spec.Replica.Kind, cachev1alpha1.KindStatefulSet))
+}
@@ -9,22 +9,61 @@ import (
 type RedisResourceBuilder struct {
 	Instance *cachev1alpha1.Redis
 	Scheme   *runtime.Scheme
+	// Image of the operator, master pods restore RDB file with it
+	RestoreImage string
+}
+
+// Outcome tells the controller what to do with the resource of a builder
+type Outcome int
+
+const (
+	// OutcomeDeploy creates the resource or updates it to match the spec
+	OutcomeDeploy Outcome = iota
+	// OutcomeRemove deletes the resource if it exists and is owned by Redis
+	OutcomeRemove
+)
+
+func (outcome Outcome) String() string {
+	if outcome == OutcomeRemove {
+		return "remove"
+	}
+	return "deploy"
 }
 
 type ResourceBuilder interface {
 	Build() (client.Object, error)
 	Update(client.Object) error
-	IsDeployed() bool
+	// Outcome tells if the resource should exist for the current spec or be removed
+	Outcome() Outcome
+}
+
+// deployedIf returns OutcomeDeploy if the resource should exist, OutcomeRemove otherwise
+func deployedIf(deployed bool) Outcome {
+	if deployed {
+		return OutcomeDeploy
+	}
+	return OutcomeRemove
 }
 
 func (builder *RedisResourceBuilder) ResourceBuilders() []ResourceBuilder {
 
 	builders := []ResourceBuilder{
 		builder.RedisAuthSecret(),
+		builder.RedisTLSSecret(),
+		builder.RedisConfigMap(),
 		builder.RedisMasterService(),
 		builder.RedisMasterDeployment(),
+		builder.RedisMasterStatefulSet(),
+		builder.RedisMasterPodDisruptionBudget(),
 		builder.RedisReplicaService(),
 		builder.RedisReplicaDeployment(),
+		builder.RedisReplicaStatefulSet(),
+		builder.RedisReplicaPodDisruptionBudget(),
+		builder.RedisPodDisruptionBudget(),
+		builder.RedisSentinelService(),
+		builder.RedisSentinelDeployment(),
+		builder.RedisClusterService(),
 	}
+	builders = append(builders, builder.RedisClusterShards()...)
 	return builders
 }
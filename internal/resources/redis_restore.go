@@ -0,0 +1,84 @@
+package resources
+
+import (
+	"github.com/avekrivoy/redis-operator/internal/storage"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+const restoreContainerName = "restore"
+
+// isRestoreEnabled checks if master pods are bootstrapped from RDB file and
+// have not loaded it yet
+func (builder *RedisResourceBuilder) isRestoreEnabled() bool {
+	return builder.Instance.IsRestoring() && !builder.isClusterMode()
+}
+
+// restoreContainer returns init container downloading RDB file into Redis data
+// directory with the operator binary. Data directory holding the downloaded
+// file is left as is, so pods restarted while Redis loads it do not download
+// it again.
+func (builder *RedisResourceBuilder) restoreContainer() corev1.Container {
+	restore := builder.Instance.Spec.Restore
+	args := []string{
+		"restore",
+		"--endpoint=" + restore.Endpoint,
+		"--region=" + restore.Region,
+		"--location=" + restore.Location,
+		"--data-dir=" + redisDataPath,
+	}
+	if restore.Checksum != "" {
+		args = append(args, "--checksum="+restore.Checksum)
+	}
+	credential := func(key string) corev1.EnvVar {
+		return corev1.EnvVar{
+			Name: key,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: restore.CredentialsSecret},
+					Key:                  key,
+				},
+			},
+		}
+	}
+	return corev1.Container{
+		Name:    restoreContainerName,
+		Image:   builder.RestoreImage,
+		Command: []string{"/manager"},
+		Args:    args,
+		Env: []corev1.EnvVar{
+			credential(storage.AccessKeyIDKey),
+			credential(storage.SecretAccessKeyKey),
+		},
+		VolumeMounts: []corev1.VolumeMount{{
+			Name:      redisDataVolume,
+			MountPath: redisDataPath,
+		}},
+		SecurityContext: &corev1.SecurityContext{
+			RunAsUser:                ptr.To[int64](redisUser),
+			RunAsNonRoot:             ptr.To(true),
+			AllowPrivilegeEscalation: ptr.To(false),
+		},
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+	}
+}
+
+// setRestore adds init container restoring RDB file into the claimed volume of
+// master pods. It is removed once the restore is completed, which restarts
+// master pods once, so later restarts keep data written after the restore.
+func (builder *RedisResourceBuilder) setRestore(podSpec *corev1.PodSpec) {
+	if !builder.isRestoreEnabled() {
+		return
+	}
+	podSpec.InitContainers = append(podSpec.InitContainers, builder.restoreContainer())
+	setDataFSGroup(podSpec)
+}
+
+// replicaCount returns number of replica pods, replicas are started once
+// master has loaded the restored dataset and do not sync an empty one
+func (builder *RedisResourceBuilder) replicaCount() *int32 {
+	if builder.Instance.IsRestoring() {
+		return ptr.To[int32](0)
+	}
+	return &builder.Instance.Spec.Replica.Count
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+)
+
+var _ = Describe("Redis restore", func() {
+	var builder *RedisResourceBuilder
+
+	BeforeEach(func() {
+		builder = &RedisResourceBuilder{Instance: newTestInstance(), Scheme: newTestScheme(), RestoreImage: "operator:test"}
+		builder.Instance.Spec.Master.Kind = cachev1alpha1.KindStatefulSet
+		builder.Instance.Spec.Replica.Count = 2
+		builder.Instance.Spec.Restore = &cachev1alpha1.RedisRestoreSpec{
+			Endpoint:          "http://minio.minio:9000",
+			Region:            "us-east-1",
+			CredentialsSecret: "backup-credentials",
+			Location:          "s3://backups/default/cache/daily.rdb",
+		}
+	})
+
+	It("should download RDB file into the claimed volume before master starts", func() {
+		statefulSet := updated(builder.RedisMasterStatefulSet()).(*appsv1.StatefulSet)
+		podSpec := statefulSet.Spec.Template.Spec
+		Expect(podSpec.InitContainers).To(HaveLen(1))
+		restore := podSpec.InitContainers[0]
+		Expect(restore.Image).To(Equal("operator:test"))
+		Expect(restore.Args).To(ContainElements("restore", "--location=s3://backups/default/cache/daily.rdb", "--data-dir=/bitnami/redis/data"))
+		Expect(restore.Env).To(ContainElement(HaveField("ValueFrom.SecretKeyRef.Name", "backup-credentials")))
+		Expect(restore.VolumeMounts).To(ContainElement(HaveField("MountPath", "/bitnami/redis/data")))
+		Expect(*podSpec.SecurityContext.FSGroup).To(BeEquivalentTo(1001))
+
+		Expect(statefulSet.Spec.VolumeClaimTemplates).To(ContainElement(HaveField("Name", "redis-data")))
+		Expect(podSpec.Volumes).NotTo(ContainElement(HaveField("Name", "redis-data")))
+	})
+
+	It("should not download RDB file again once the restore is completed", func() {
+		builder.Instance.Status.Restore = &cachev1alpha1.RedisRestoreStatus{Phase: cachev1alpha1.RestorePhaseLoading}
+		statefulSet := updated(builder.RedisMasterStatefulSet()).(*appsv1.StatefulSet)
+		Expect(statefulSet.Spec.Template.Spec.InitContainers).To(HaveLen(1))
+
+		builder.Instance.Status.Restore.Phase = cachev1alpha1.RestorePhaseCompleted
+		statefulSet = updated(builder.RedisMasterStatefulSet()).(*appsv1.StatefulSet)
+		Expect(statefulSet.Spec.Template.Spec.InitContainers).To(BeEmpty())
+		Expect(statefulSet.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(HaveField("MountPath", "/bitnami/redis/data")))
+	})
+
+	It("should start replicas once master has loaded the RDB file", func() {
+		deployment := updated(builder.RedisReplicaDeployment()).(*appsv1.Deployment)
+		Expect(*deployment.Spec.Replicas).To(BeEquivalentTo(0))
+		Expect(deployment.Spec.Template.Spec.InitContainers).To(BeEmpty())
+
+		builder.Instance.Status.Restore = &cachev1alpha1.RedisRestoreStatus{Phase: cachev1alpha1.RestorePhaseCompleted}
+		deployment = updated(builder.RedisReplicaDeployment()).(*appsv1.Deployment)
+		Expect(*deployment.Spec.Replicas).To(BeEquivalentTo(2))
+	})
+
+	It("should keep master pods unchanged without restore", func() {
+		builder.Instance.Spec.Restore = nil
+		builder.Instance.Spec.Master.Kind = cachev1alpha1.KindDeployment
+		deployment := updated(builder.RedisMasterDeployment()).(*appsv1.Deployment)
+		Expect(deployment.Spec.Template.Spec.InitContainers).To(BeEmpty())
+		Expect(deployment.Spec.Template.Spec.Volumes).NotTo(ContainElement(HaveField("Name", "redis-data")))
+	})
+})
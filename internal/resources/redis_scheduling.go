@@ -0,0 +1,74 @@
+package resources
+
+import (
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	metadata "github.com/avekrivoy/redis-operator/internal/metadata"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Weights of default anti-affinity, losing a node is more likely than losing a zone
+const (
+	nodeAntiAffinityWeight = 100
+	zoneAntiAffinityWeight = 50
+)
+
+// peerComponents returns components whose pods should not share a node with
+// pods of the component: master and replicas of the same Redis hold the same
+// data, so they are spread together
+func peerComponents(component string) []string {
+	switch component {
+	case metadata.RedisMasterComponent(), metadata.RedisReplicaComponent():
+		return []string{metadata.RedisMasterComponent(), metadata.RedisReplicaComponent()}
+	}
+	return []string{component}
+}
+
+// defaultPodAntiAffinity prefers nodes and zones without pods of peer components
+func (builder *RedisResourceBuilder) defaultPodAntiAffinity(component string) *corev1.PodAntiAffinity {
+	selector := &metav1.LabelSelector{
+		MatchLabels: metadata.CommonLabels(builder.Instance.Name),
+		MatchExpressions: []metav1.LabelSelectorRequirement{{
+			Key:      "app.kubernetes.io/component",
+			Operator: metav1.LabelSelectorOpIn,
+			Values:   peerComponents(component),
+		}},
+	}
+	return &corev1.PodAntiAffinity{
+		PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+			{
+				Weight: nodeAntiAffinityWeight,
+				PodAffinityTerm: corev1.PodAffinityTerm{
+					LabelSelector: selector,
+					TopologyKey:   corev1.LabelHostname,
+				},
+			},
+			{
+				Weight: zoneAntiAffinityWeight,
+				PodAffinityTerm: corev1.PodAffinityTerm{
+					LabelSelector: selector,
+					TopologyKey:   corev1.LabelTopologyZone,
+				},
+			},
+		},
+	}
+}
+
+// setScheduling applies scheduling of the role to pods of the component. Pod
+// anti-affinity defaults to spreading the pods away from their peers.
+func (builder *RedisResourceBuilder) setScheduling(podSpec *corev1.PodSpec, scheduling cachev1alpha1.RedisSchedulingSpec, component string) {
+	podSpec.NodeSelector = scheduling.NodeSelector
+	podSpec.Tolerations = scheduling.Tolerations
+	podSpec.TopologySpreadConstraints = scheduling.TopologySpreadConstraints
+	podSpec.PriorityClassName = scheduling.PriorityClassName
+	podSpec.RuntimeClassName = scheduling.RuntimeClassName
+
+	affinity := &corev1.Affinity{}
+	if scheduling.Affinity != nil {
+		affinity = scheduling.Affinity.DeepCopy()
+	}
+	if affinity.PodAntiAffinity == nil {
+		affinity.PodAntiAffinity = builder.defaultPodAntiAffinity(component)
+	}
+	podSpec.Affinity = affinity
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+var _ = Describe("Redis scheduling", func() {
+	var builder *RedisResourceBuilder
+
+	BeforeEach(func() {
+		builder = &RedisResourceBuilder{Instance: newTestInstance(), Scheme: newTestScheme()}
+	})
+
+	It("should spread master and replicas across nodes and zones by default", func() {
+		podSpec := updated(builder.RedisReplicaDeployment()).(*appsv1.Deployment).Spec.Template.Spec
+		terms := podSpec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+		Expect(terms).To(HaveLen(2))
+		Expect(terms[0].PodAffinityTerm.TopologyKey).To(Equal(corev1.LabelHostname))
+		Expect(terms[1].PodAffinityTerm.TopologyKey).To(Equal(corev1.LabelTopologyZone))
+
+		selector := terms[0].PodAffinityTerm.LabelSelector
+		Expect(selector.MatchLabels).To(HaveKeyWithValue("app.kubernetes.io/name", "test-redis"))
+		Expect(selector.MatchExpressions[0].Values).To(ConsistOf("redis-master", "redis-replica"))
+	})
+
+	It("should apply scheduling of the role", func() {
+		master := &builder.Instance.Spec.Master
+		master.NodeSelector = map[string]string{"pool": "redis"}
+		master.Tolerations = []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}}
+		master.PriorityClassName = "high-priority"
+		master.RuntimeClassName = ptr.To("gvisor")
+		master.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{}}
+
+		podSpec := updated(builder.RedisMasterDeployment()).(*appsv1.Deployment).Spec.Template.Spec
+		Expect(podSpec.NodeSelector).To(Equal(master.NodeSelector))
+		Expect(podSpec.Tolerations).To(Equal(master.Tolerations))
+		Expect(podSpec.PriorityClassName).To(Equal("high-priority"))
+		Expect(*podSpec.RuntimeClassName).To(Equal("gvisor"))
+		Expect(podSpec.Affinity.NodeAffinity).NotTo(BeNil())
+		Expect(podSpec.Affinity.PodAntiAffinity).NotTo(BeNil())
+		Expect(master.Affinity.PodAntiAffinity).To(BeNil())
+	})
+
+	It("should keep pod anti-affinity of the spec", func() {
+		antiAffinity := &corev1.PodAntiAffinity{}
+		builder.Instance.Spec.Master.Affinity = &corev1.Affinity{PodAntiAffinity: antiAffinity}
+		Expect(updated(builder.RedisMasterDeployment()).(*appsv1.Deployment).Spec.Template.Spec.Affinity.PodAntiAffinity).To(Equal(antiAffinity))
+	})
+
+	It("should spread Sentinel pods apart from each other", func() {
+		builder.Instance.Spec.Sentinel.Enabled = true
+		builder.Instance.Spec.Sentinel.Count = 3
+		terms := updated(builder.RedisSentinelDeployment()).(*appsv1.Deployment).Spec.Template.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+		Expect(terms[0].PodAffinityTerm.LabelSelector.MatchExpressions[0].Values).To(ConsistOf("redis-sentinel"))
+	})
+})
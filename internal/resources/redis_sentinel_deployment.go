@@ -0,0 +1,111 @@
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	metadata "github.com/avekrivoy/redis-operator/internal/metadata"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	RedisSentinelPort = 26379
+	sentinelConfigDir = "/opt/sentinel"
+)
+
+type RedisSentinelDeploymentBuilder struct {
+	*RedisResourceBuilder
+}
+
+func (builder *RedisResourceBuilder) RedisSentinelDeployment() *RedisSentinelDeploymentBuilder {
+	return &RedisSentinelDeploymentBuilder{builder}
+}
+
+func (builder *RedisSentinelDeploymentBuilder) Build() (client.Object, error) {
+	component := metadata.RedisSentinelComponent()
+	deploymentName := fmt.Sprintf("%s-%s", builder.Instance.Name, component)
+
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      deploymentName,
+			Namespace: builder.Instance.Namespace,
+		},
+	}, nil
+}
+
+func (builder *RedisSentinelDeploymentBuilder) Update(object client.Object) error {
+	component := metadata.RedisSentinelComponent()
+
+	deploymentLabels := metadata.Label{
+		"app.kubernetes.io/component": component,
+	}
+
+	labels := metadata.ResourceLabels(builder.Instance.Name, deploymentLabels)
+
+	// Sentinel starts without monitored masters, the operator configures
+	// monitoring with SENTINEL MONITOR once Redis pods are running.
+	// Sentinel rewrites its config file, so it is kept on a writable volume.
+	config := []string{fmt.Sprintf("port %d", RedisSentinelPort)}
+	if builder.isTLSEnabled() {
+		config = builder.sentinelTLSConfig(RedisSentinelPort)
+	}
+	startSentinel := fmt.Sprintf("printf '%%s\\n' '%s' > %[2]s/sentinel.conf && exec redis-server %[2]s/sentinel.conf --sentinel",
+		strings.Join(config, "' '"), sentinelConfigDir)
+
+	deployment := object.(*appsv1.Deployment)
+	deployment.ObjectMeta.Labels = labels
+	deployment.Spec.Replicas = &builder.Instance.Spec.Sentinel.Count
+	deployment.Spec.Template.ObjectMeta.Labels = labels
+	deployment.Spec.Selector = &metav1.LabelSelector{
+		MatchLabels: metadata.LabelSelector(builder.Instance.Name, component),
+	}
+	deployment.Spec.Template.Spec = corev1.PodSpec{
+		ImagePullSecrets: builder.Instance.Spec.Common.Image.ImagePullSecrets,
+		Containers: []corev1.Container{{
+			Image:           builder.redisImage(),
+			ImagePullPolicy: corev1.PullPolicy(builder.Instance.Spec.Common.Image.ImagePullPolicy),
+			Name:            "sentinel",
+			Command:         []string{"/bin/sh", "-c"},
+			Args:            []string{startSentinel},
+			Ports: []corev1.ContainerPort{{
+				ContainerPort: RedisSentinelPort,
+				Name:          "sentinel",
+			}},
+			VolumeMounts: []corev1.VolumeMount{{
+				Name:      "sentinel-config",
+				MountPath: sentinelConfigDir,
+			}},
+		}},
+		Volumes: []corev1.Volume{{
+			Name: "sentinel-config",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		}},
+	}
+
+	builder.setScheduling(&deployment.Spec.Template.Spec, builder.Instance.Spec.Sentinel.RedisSchedulingSpec, component)
+
+	if builder.isTLSEnabled() {
+		builder.mountTLS(&deployment.Spec.Template.Spec, "sentinel")
+		// Sentinel loads certificates on start only
+		if tlsStatus := builder.Instance.Status.TLS; tlsStatus != nil && tlsStatus.SerialNumber != "" {
+			deployment.Spec.Template.ObjectMeta.Annotations = map[string]string{
+				metadata.TLSSerialAnnotation: tlsStatus.SerialNumber,
+			}
+		}
+	}
+
+	if err := controllerutil.SetControllerReference(builder.Instance, deployment, builder.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+	return nil
+}
+
+func (builder *RedisSentinelDeploymentBuilder) Outcome() Outcome {
+	return deployedIf(!builder.isClusterMode() && builder.Instance.Spec.Sentinel.Enabled && builder.Instance.Spec.Sentinel.Count >= 1)
+}
@@ -0,0 +1,62 @@
+package resources
+
+import (
+	"fmt"
+
+	metadata "github.com/avekrivoy/redis-operator/internal/metadata"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+type RedisSentinelServiceBuilder struct {
+	*RedisResourceBuilder
+}
+
+func (builder *RedisResourceBuilder) RedisSentinelService() *RedisSentinelServiceBuilder {
+	return &RedisSentinelServiceBuilder{builder}
+}
+
+func (builder *RedisSentinelServiceBuilder) Build() (client.Object, error) {
+	component := metadata.RedisSentinelComponent()
+	svcName := metadata.RedisServiceName(builder.Instance.Name, component)
+
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      svcName,
+			Namespace: builder.Instance.Namespace,
+		},
+	}, nil
+}
+
+func (builder *RedisSentinelServiceBuilder) Update(object client.Object) error {
+	component := metadata.RedisSentinelComponent()
+	svcLabels := metadata.Label{
+		"app.kubernetes.io/component": component,
+	}
+
+	svc := object.(*corev1.Service)
+	svc.Labels = metadata.ResourceLabels(builder.Instance.Name, svcLabels)
+
+	svc.Spec = corev1.ServiceSpec{
+		Selector: metadata.LabelSelector(builder.Instance.Name, component),
+		Ports: []corev1.ServicePort{
+			{
+				Port:     RedisSentinelPort,
+				Protocol: corev1.ProtocolTCP,
+			},
+		},
+		Type: corev1.ServiceTypeClusterIP,
+	}
+
+	if err := controllerutil.SetControllerReference(builder.Instance, svc, builder.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+
+	return nil
+}
+
+func (builder *RedisSentinelServiceBuilder) Outcome() Outcome {
+	return deployedIf(!builder.isClusterMode() && builder.Instance.Spec.Sentinel.Enabled && builder.Instance.Spec.Sentinel.Count >= 1)
+}
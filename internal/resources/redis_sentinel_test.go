@@ -0,0 +1,54 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/avekrivoy/redis-operator/internal/metadata"
+)
+
+var _ = Describe("Redis Sentinel builders", func() {
+	var builder *RedisResourceBuilder
+
+	BeforeEach(func() {
+		instance := newTestInstance()
+		instance.Spec.Replica.Count = 2
+		instance.Spec.Sentinel.Count = 3
+		builder = &RedisResourceBuilder{Instance: instance, Scheme: newTestScheme()}
+	})
+
+	It("should deploy Sentinel only when enabled", func() {
+		Expect(builder.RedisSentinelDeployment().Outcome()).To(Equal(OutcomeRemove))
+		Expect(builder.RedisSentinelService().Outcome()).To(Equal(OutcomeRemove))
+
+		builder.Instance.Spec.Sentinel.Enabled = true
+		Expect(builder.RedisSentinelDeployment().Outcome()).To(Equal(OutcomeDeploy))
+		Expect(builder.RedisSentinelService().Outcome()).To(Equal(OutcomeDeploy))
+	})
+
+	It("should select master pod by role label in Sentinel mode", func() {
+		builder.Instance.Spec.Sentinel.Enabled = true
+
+		selector := updated(builder.RedisMasterService()).(*corev1.Service).Spec.Selector
+		Expect(selector).To(HaveKeyWithValue(metadata.RoleLabel, metadata.RoleMaster))
+		Expect(selector).NotTo(HaveKey("app.kubernetes.io/component"))
+	})
+})
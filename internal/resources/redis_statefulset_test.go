@@ -0,0 +1,72 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+)
+
+var _ = Describe("Redis StatefulSet builders", func() {
+	var builder *RedisResourceBuilder
+
+	BeforeEach(func() {
+		instance := newTestInstance()
+		instance.Spec.Master.Kind = cachev1alpha1.KindStatefulSet
+		instance.Spec.Master.Persistence = cachev1alpha1.RedisPersistenceSpec{
+			Size:        resource.MustParse("1Gi"),
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+		}
+		instance.Spec.Replica.Count = 2
+		builder = &RedisResourceBuilder{Instance: instance, Scheme: newTestScheme()}
+	})
+
+	It("should render master StatefulSet instead of Deployment", func() {
+		Expect(builder.RedisMasterStatefulSet().Outcome()).To(Equal(OutcomeDeploy))
+		Expect(builder.RedisMasterDeployment().Outcome()).To(Equal(OutcomeRemove))
+		Expect(builder.RedisReplicaStatefulSet().Outcome()).To(Equal(OutcomeRemove))
+		Expect(builder.RedisReplicaDeployment().Outcome()).To(Equal(OutcomeDeploy))
+	})
+
+	It("should claim persistent volume for Redis data", func() {
+		sts := updated(builder.RedisMasterStatefulSet()).(*appsv1.StatefulSet)
+		Expect(sts.Name).To(Equal("test-redis-redis-master"))
+		Expect(sts.Spec.ServiceName).To(Equal("test-redis-redis-master"))
+		Expect(sts.Spec.VolumeClaimTemplates).To(HaveLen(1))
+
+		claim := sts.Spec.VolumeClaimTemplates[0]
+		Expect(*claim.Spec.StorageClassName).To(Equal("standard"))
+		Expect(claim.Spec.AccessModes).To(ConsistOf(corev1.ReadWriteOnce))
+		Expect(claim.Spec.Resources.Requests.Storage().String()).To(Equal("1Gi"))
+
+		container := sts.Spec.Template.Spec.Containers[0]
+		Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name:      claim.Name,
+			MountPath: "/bitnami/redis/data",
+		}))
+		securityContext := sts.Spec.Template.Spec.SecurityContext
+		Expect(securityContext).NotTo(BeNil())
+		Expect(*securityContext.FSGroup).To(BeEquivalentTo(1001))
+		Expect(*securityContext.FSGroupChangePolicy).To(Equal(corev1.FSGroupChangeOnRootMismatch))
+	})
+})
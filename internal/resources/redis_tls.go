@@ -0,0 +1,126 @@
+package resources
+
+import (
+	"fmt"
+	"path"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	metadata "github.com/avekrivoy/redis-operator/internal/metadata"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	redisTLSVolume = "redis-tls"
+	// RedisTLSPath is the directory certificates are mounted to in Redis and Sentinel pods
+	RedisTLSPath = "/opt/bitnami/redis/certs"
+)
+
+// TLSSecretName returns name of the secret with tls.crt, tls.key and ca.crt used by Redis pods
+func TLSSecretName(instance *cachev1alpha1.Redis) string {
+	if instance.Spec.Common.TLS.ExistingSecret == "" {
+		return metadata.RedisTLSSecretName(instance.Name)
+	}
+	return instance.Spec.Common.TLS.ExistingSecret
+}
+
+// isTLSEnabled checks if Redis pods serve TLS only
+func (builder *RedisResourceBuilder) isTLSEnabled() bool {
+	return builder.Instance.Spec.Common.TLS.Enabled
+}
+
+// tlsFile returns path of the mounted certificate file
+func tlsFile(key string) string {
+	return path.Join(RedisTLSPath, key)
+}
+
+// mountTLS mounts certificates into the container of the pod. CA private key
+// of the generated secret is not mounted.
+func (builder *RedisResourceBuilder) mountTLS(podSpec *corev1.PodSpec, containerName string) {
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: redisTLSVolume,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: TLSSecretName(builder.Instance),
+				Items: []corev1.KeyToPath{
+					{Key: corev1.TLSCertKey, Path: corev1.TLSCertKey},
+					{Key: corev1.TLSPrivateKeyKey, Path: corev1.TLSPrivateKeyKey},
+					{Key: TLSCAKey, Path: TLSCAKey},
+				},
+			},
+		},
+	})
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name != containerName {
+			continue
+		}
+		podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, corev1.VolumeMount{
+			Name:      redisTLSVolume,
+			MountPath: RedisTLSPath,
+			ReadOnly:  true,
+		})
+	}
+}
+
+// enableTLS makes Redis container serve TLS on the Redis port instead of plain
+// TCP and use TLS for replication and cluster bus
+func (builder *RedisResourceBuilder) enableTLS(podSpec *corev1.PodSpec) {
+	if !builder.isTLSEnabled() {
+		return
+	}
+	builder.mountTLS(podSpec, redisContainerName)
+
+	authClients := "no"
+	if builder.Instance.Spec.Common.TLS.AuthClients {
+		authClients = "yes"
+	}
+	tlsFlags := "--tls-replication yes"
+	if builder.isClusterMode() {
+		tlsFlags += " --tls-cluster yes"
+	}
+
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		if container.Name != redisContainerName {
+			continue
+		}
+		// Bitnami image disables plain port when TLS uses the default port
+		container.Env = append(container.Env,
+			corev1.EnvVar{Name: "REDIS_TLS_ENABLED", Value: "yes"},
+			corev1.EnvVar{Name: "REDIS_TLS_PORT_NUMBER", Value: "6379"},
+			corev1.EnvVar{Name: "REDIS_TLS_CERT_FILE", Value: tlsFile(corev1.TLSCertKey)},
+			corev1.EnvVar{Name: "REDIS_TLS_KEY_FILE", Value: tlsFile(corev1.TLSPrivateKeyKey)},
+			corev1.EnvVar{Name: "REDIS_TLS_CA_FILE", Value: tlsFile(TLSCAKey)},
+			corev1.EnvVar{Name: "REDIS_TLS_AUTH_CLIENTS", Value: authClients},
+		)
+		setExtraFlags(container, tlsFlags)
+	}
+}
+
+// sentinelTLSConfig returns Sentinel config lines serving Sentinel port over
+// TLS and connecting to Redis pods with TLS
+func (builder *RedisResourceBuilder) sentinelTLSConfig(port int) []string {
+	authClients := "no"
+	if builder.Instance.Spec.Common.TLS.AuthClients {
+		authClients = "yes"
+	}
+	return []string{
+		"port 0",
+		fmt.Sprintf("tls-port %d", port),
+		"tls-cert-file " + tlsFile(corev1.TLSCertKey),
+		"tls-key-file " + tlsFile(corev1.TLSPrivateKeyKey),
+		"tls-ca-cert-file " + tlsFile(TLSCAKey),
+		"tls-auth-clients " + authClients,
+		"tls-replication yes",
+	}
+}
+
+// setExtraFlags appends flags to REDIS_EXTRA_FLAGS passed by Bitnami image to redis-server
+func setExtraFlags(container *corev1.Container, flags string) {
+	for i := range container.Env {
+		if container.Env[i].Name == "REDIS_EXTRA_FLAGS" {
+			container.Env[i].Value += " " + flags
+			return
+		}
+	}
+	container.Env = append(container.Env, corev1.EnvVar{Name: "REDIS_EXTRA_FLAGS", Value: flags})
+}
@@ -0,0 +1,213 @@
+package resources
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"slices"
+	"time"
+
+	metadata "github.com/avekrivoy/redis-operator/internal/metadata"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// TLSCAKey holds CA certificate clients verify Redis certificate with
+	TLSCAKey = "ca.crt"
+	// tlsCAPrivateKeyKey holds private key of the generated CA, it is not mounted into pods
+	tlsCAPrivateKeyKey = "ca.key"
+	caDuration         = 10 * 365 * 24 * time.Hour
+)
+
+type RedisTLSSecretBuilder struct {
+	*RedisResourceBuilder
+}
+
+func (builder *RedisResourceBuilder) RedisTLSSecret() *RedisTLSSecretBuilder {
+	return &RedisTLSSecretBuilder{builder}
+}
+
+func (builder *RedisTLSSecretBuilder) Build() (client.Object, error) {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      metadata.RedisTLSSecretName(builder.Instance.Name),
+			Namespace: builder.Instance.Namespace,
+		},
+		Type: corev1.SecretTypeTLS,
+	}, nil
+}
+
+// Update generates self-signed CA and serving certificate signed by it. The
+// certificate is issued again when it is about to expire or Service names change.
+func (builder *RedisTLSSecretBuilder) Update(object client.Object) error {
+	secret := object.(*corev1.Secret)
+	secret.Labels = metadata.ResourceLabels(builder.Instance.Name, metadata.Label{
+		"app.kubernetes.io/component": metadata.DefaultComponent,
+	})
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+
+	tlsSpec := builder.Instance.Spec.Common.TLS
+	now := time.Now()
+	renewBefore := tlsSpec.GetRenewBefore()
+
+	caCert, caErr := ParseCertificate(secret.Data[TLSCAKey])
+	if caErr != nil || len(secret.Data[tlsCAPrivateKeyKey]) == 0 || now.Add(renewBefore).After(caCert.NotAfter) {
+		caPEM, caKeyPEM, err := generateCA(builder.Instance.Name+"-redis-ca", now)
+		if err != nil {
+			return err
+		}
+		secret.Data[TLSCAKey] = caPEM
+		secret.Data[tlsCAPrivateKeyKey] = caKeyPEM
+		// Certificate signed by the previous CA has to be issued again
+		delete(secret.Data, corev1.TLSCertKey)
+	}
+
+	dnsNames := builder.tlsDNSNames()
+	cert, err := ParseCertificate(secret.Data[corev1.TLSCertKey])
+	if err != nil || now.Add(renewBefore).After(cert.NotAfter) || !slices.Equal(cert.DNSNames, dnsNames) {
+		certPEM, keyPEM, err := issueCertificate(secret.Data[TLSCAKey], secret.Data[tlsCAPrivateKeyKey], dnsNames, now, tlsSpec.GetCertificateDuration())
+		if err != nil {
+			return err
+		}
+		secret.Data[corev1.TLSCertKey] = certPEM
+		secret.Data[corev1.TLSPrivateKeyKey] = keyPEM
+	}
+
+	if err := controllerutil.SetControllerReference(builder.Instance, secret, builder.Scheme); err != nil {
+		return fmt.Errorf("failed setting controller reference: %w", err)
+	}
+	return nil
+}
+
+func (builder *RedisTLSSecretBuilder) Outcome() Outcome {
+	tlsSpec := builder.Instance.Spec.Common.TLS
+	return deployedIf(tlsSpec.Enabled && tlsSpec.ExistingSecret == "")
+}
+
+// tlsDNSNames returns names of Redis Services and pods behind them the serving certificate is valid for
+func (builder *RedisResourceBuilder) tlsDNSNames() []string {
+	names := []string{}
+	for _, component := range []string{metadata.RedisMasterComponent(), metadata.RedisReplicaComponent(), metadata.RedisSentinelComponent(), metadata.RedisClusterComponent()} {
+		service := metadata.RedisServiceName(builder.Instance.Name, component)
+		namespaced := fmt.Sprintf("%s.%s", service, builder.Instance.Namespace)
+		names = append(names,
+			service,
+			namespaced,
+			namespaced+".svc",
+			namespaced+".svc.cluster.local",
+			// StatefulSet pods get DNS names under the Service
+			"*."+namespaced+".svc",
+			"*."+namespaced+".svc.cluster.local",
+		)
+	}
+	return append(names, "localhost")
+}
+
+// ParseCertificate parses the first certificate of PEM data
+func ParseCertificate(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("no PEM encoded certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// generateCA returns PEM encoded self-signed CA certificate and its private key
+func generateCA(commonName string, now time.Time) ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caDuration),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return encodeCertificate(der, key)
+}
+
+// issueCertificate returns PEM encoded certificate signed by the CA and its
+// private key. It is used by Redis servers and by the operator as a client.
+func issueCertificate(caPEM []byte, caKeyPEM []byte, dnsNames []string, now time.Time, duration time.Duration) ([]byte, []byte, error) {
+	caCert, err := ParseCertificate(caPEM)
+	if err != nil {
+		return nil, nil, err
+	}
+	block, _ := pem.Decode(caKeyPEM)
+	if block == nil {
+		return nil, nil, errors.New("no PEM encoded CA key found")
+	}
+	caKey, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:     dnsNames,
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(duration),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return encodeCertificate(der, key)
+}
+
+func encodeCertificate(der []byte, key *ecdsa.PrivateKey) ([]byte, []byte, error) {
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	certPEM := &bytes.Buffer{}
+	if err := pem.Encode(certPEM, &pem.Block{Type: "CERTIFICATE", Bytes: der}); err != nil {
+		return nil, nil, err
+	}
+	keyPEM := &bytes.Buffer{}
+	if err := pem.Encode(keyPEM, &pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}); err != nil {
+		return nil, nil, err
+	}
+	return certPEM.Bytes(), keyPEM.Bytes(), nil
+}
+
+func randomSerial() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
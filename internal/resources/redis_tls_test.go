@@ -0,0 +1,108 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"crypto/x509"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Redis TLS", func() {
+	var builder *RedisResourceBuilder
+
+	BeforeEach(func() {
+		instance := newTestInstance()
+		instance.Spec.Common.TLS.Enabled = true
+		builder = &RedisResourceBuilder{Instance: instance, Scheme: newTestScheme()}
+	})
+
+	updatedSecret := func(secret *corev1.Secret) *corev1.Secret {
+		secretBuilder := builder.RedisTLSSecret()
+		if secret == nil {
+			object, err := secretBuilder.Build()
+			Expect(err).NotTo(HaveOccurred())
+			secret = object.(*corev1.Secret)
+		}
+		Expect(secretBuilder.Update(secret)).To(Succeed())
+		return secret
+	}
+
+	It("should generate certificate signed by generated CA", func() {
+		Expect(builder.RedisTLSSecret().Outcome()).To(Equal(OutcomeDeploy))
+		secret := updatedSecret(nil)
+
+		caCert, err := ParseCertificate(secret.Data[TLSCAKey])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(caCert.IsCA).To(BeTrue())
+		cert, err := ParseCertificate(secret.Data[corev1.TLSCertKey])
+		Expect(err).NotTo(HaveOccurred())
+
+		roots := x509.NewCertPool()
+		roots.AddCert(caCert)
+		_, err = cert.Verify(x509.VerifyOptions{
+			DNSName: builder.Instance.Name + "-redis-master." + builder.Instance.Namespace + ".svc",
+			Roots:   roots,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cert.NotAfter).To(BeTemporally("~", time.Now().Add(90*24*time.Hour), time.Hour))
+	})
+
+	It("should keep certificate until it has to be renewed", func() {
+		secret := updatedSecret(nil)
+		issued := secret.Data[corev1.TLSCertKey]
+		Expect(updatedSecret(secret).Data[corev1.TLSCertKey]).To(Equal(issued))
+
+		builder.Instance.Spec.Common.TLS.RenewBefore = &metav1.Duration{Duration: 91 * 24 * time.Hour}
+		builder.Instance.Spec.Common.TLS.CertificateDuration = &metav1.Duration{Duration: 100 * 24 * time.Hour}
+		Expect(updatedSecret(secret).Data[corev1.TLSCertKey]).NotTo(Equal(issued))
+	})
+
+	It("should not generate certificate for existing secret", func() {
+		builder.Instance.Spec.Common.TLS.ExistingSecret = "redis-tls"
+		Expect(builder.RedisTLSSecret().Outcome()).To(Equal(OutcomeRemove))
+	})
+
+	It("should serve Redis over TLS", func() {
+		builder.Instance.Spec.Common.TLS.AuthClients = true
+		podSpec := updated(builder.RedisMasterDeployment()).(*appsv1.Deployment).Spec.Template.Spec
+		Expect(podSpec.Volumes).To(ContainElement(HaveField("Secret.SecretName", builder.Instance.Name+"-tls")))
+		Expect(podSpec.Containers[0].VolumeMounts).To(ContainElement(HaveField("MountPath", RedisTLSPath)))
+		Expect(podSpec.Containers[0].Env).To(ContainElements(
+			corev1.EnvVar{Name: "REDIS_TLS_ENABLED", Value: "yes"},
+			corev1.EnvVar{Name: "REDIS_TLS_AUTH_CLIENTS", Value: "yes"},
+		))
+		Expect(podSpec.Containers[0].Env).To(ContainElement(And(
+			HaveField("Name", "REDIS_EXTRA_FLAGS"),
+			HaveField("Value", ContainSubstring("--tls-replication yes")),
+		)))
+	})
+
+	It("should serve Sentinel over TLS", func() {
+		builder.Instance.Spec.Sentinel.Enabled = true
+		container := updated(builder.RedisSentinelDeployment()).(*appsv1.Deployment).Spec.Template.Spec.Containers[0]
+		Expect(container.Args[0]).To(ContainSubstring("'port 0' 'tls-port 26379'"))
+		Expect(container.Args[0]).To(ContainSubstring("'tls-replication yes'"))
+		Expect(container.VolumeMounts).To(ContainElement(HaveField("MountPath", RedisTLSPath)))
+	})
+})
@@ -0,0 +1,85 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+)
+
+func TestResources(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Resources Suite")
+}
+
+func newTestScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	Expect(cachev1alpha1.AddToScheme(scheme)).To(Succeed())
+	return scheme
+}
+
+// updated returns object rendered by the builder for the current spec
+func updated(builder ResourceBuilder) client.Object {
+	object, err := builder.Build()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(builder.Update(object)).To(Succeed())
+	return object
+}
+
+// newTestInstance returns Redis instance with the same values CRD defaulting would set
+func newTestInstance() *cachev1alpha1.Redis {
+	return &cachev1alpha1.Redis{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-redis",
+			Namespace: "default",
+			UID:       "test-uid",
+		},
+		Spec: cachev1alpha1.RedisSpec{
+			Common: cachev1alpha1.RedisCommonSpec{
+				Image: cachev1alpha1.RedisImageSpec{
+					ImageRepository: "bitnami/redis",
+					ImageTag:        "7.2.5",
+					ImagePullPolicy: "IfNotPresent",
+				},
+				StorageClass: "standard",
+				Auth: cachev1alpha1.RedisAuthSpec{
+					Enabled: ptr.To(true),
+				},
+			},
+			Master: cachev1alpha1.RedisMasterSpec{
+				Count: 1,
+				Kind:  cachev1alpha1.KindDeployment,
+			},
+			Replica: cachev1alpha1.RedisReplicaSpec{
+				Count: 0,
+				Kind:  cachev1alpha1.KindDeployment,
+			},
+		},
+	}
+}
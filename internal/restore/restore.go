@@ -0,0 +1,124 @@
+package restore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/avekrivoy/redis-operator/internal/storage"
+)
+
+const (
+	rdbFile = "dump.rdb"
+	// Redis 7 keeps multi-part AOF in this directory, the base part may be an RDB file
+	aofDir          = "appendonlydir"
+	aofBaseFile     = "appendonly.aof.1.base.rdb"
+	aofManifestFile = "appendonly.aof.manifest"
+	// rdbMagic starts every RDB file
+	rdbMagic = "REDIS"
+)
+
+// Options of restoring RDB file into Redis data directory
+type Options struct {
+	// Storage the file is downloaded from, bucket is taken from the location
+	Storage storage.S3Options
+	// Location of the file, e.g. 's3://backups/redis/dump.rdb'
+	Location string
+	// Expected checksum of the file as 'sha256:<hex>', not verified if empty
+	Checksum string
+	// Redis data directory
+	DataDir string
+}
+
+// Run downloads RDB file into Redis data directory unless the directory holds
+// data already, e.g. when the pod is restarted while Redis loads the file. The file is
+// placed both as dump.rdb and as the base of multi-part AOF, so Redis loads it
+// whether AOF is enabled or not. It returns false if restore was skipped.
+func Run(ctx context.Context, options Options) (bool, error) {
+	for _, name := range []string{rdbFile, aofDir} {
+		if _, err := os.Stat(filepath.Join(options.DataDir, name)); err == nil {
+			return false, nil
+		} else if !os.IsNotExist(err) {
+			return false, err
+		}
+	}
+
+	bucket, key, err := storage.ParseLocation(options.Location)
+	if err != nil {
+		return false, err
+	}
+	storageOptions := options.Storage
+	storageOptions.Bucket = bucket
+	s3, err := storage.NewS3(storageOptions)
+	if err != nil {
+		return false, err
+	}
+	body, size, err := s3.GetObject(ctx, key)
+	if err != nil {
+		return false, fmt.Errorf("failed to download %s: %w", options.Location, err)
+	}
+	defer body.Close()
+
+	file, err := os.CreateTemp(options.DataDir, ".restore-*.rdb")
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	hash := sha256.New()
+	written, err := io.Copy(io.MultiWriter(file, hash), body)
+	if err != nil {
+		return false, fmt.Errorf("failed to download %s: %w", options.Location, err)
+	}
+	if size >= 0 && written != size {
+		return false, fmt.Errorf("downloaded %d of %d bytes of %s", written, size, options.Location)
+	}
+	checksum := "sha256:" + hex.EncodeToString(hash.Sum(nil))
+	if options.Checksum != "" && checksum != options.Checksum {
+		return false, fmt.Errorf("checksum %s of %s does not match expected %s", checksum, options.Location, options.Checksum)
+	}
+	if err := verifyRDB(file); err != nil {
+		return false, fmt.Errorf("%s is not an RDB file: %w", options.Location, err)
+	}
+	if err := file.Sync(); err != nil {
+		return false, err
+	}
+
+	// AOF base links the same file, its directory is moved into place complete
+	staging, err := os.MkdirTemp(options.DataDir, ".restore-aof-*")
+	if err != nil {
+		return false, err
+	}
+	defer os.RemoveAll(staging)
+	if err := os.Link(file.Name(), filepath.Join(staging, aofBaseFile)); err != nil {
+		return false, err
+	}
+	manifest := fmt.Sprintf("file %s seq 1 type b\n", aofBaseFile)
+	if err := os.WriteFile(filepath.Join(staging, aofManifestFile), []byte(manifest), 0o600); err != nil {
+		return false, err
+	}
+	if err := os.Rename(staging, filepath.Join(options.DataDir, aofDir)); err != nil {
+		return false, err
+	}
+	if err := os.Rename(file.Name(), filepath.Join(options.DataDir, rdbFile)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// verifyRDB checks that file starts with RDB magic string
+func verifyRDB(file *os.File) error {
+	magic := make([]byte, len(rdbMagic))
+	if _, err := file.ReadAt(magic, 0); err != nil {
+		return err
+	}
+	if string(magic) != rdbMagic {
+		return fmt.Errorf("unexpected header %q", magic)
+	}
+	return nil
+}
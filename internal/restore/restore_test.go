@@ -0,0 +1,103 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/avekrivoy/redis-operator/internal/storage"
+)
+
+var _ = Describe("Restore", func() {
+	ctx := context.Background()
+	rdb := []byte("REDIS0011\xfa\tredis-ver\x057.2.4\xff")
+	sum := sha256.Sum256(rdb)
+	checksum := "sha256:" + hex.EncodeToString(sum[:])
+
+	var server *httptest.Server
+	var options Options
+
+	BeforeEach(func() {
+		// MinIO stand-in serving a single object
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet || r.URL.Path != "/backups/redis/cache.rdb" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(rdb)
+		}))
+		options = Options{
+			Storage:  storage.S3Options{Endpoint: server.URL, AccessKeyID: "access", SecretAccessKey: "secret"},
+			Location: "s3://backups/redis/cache.rdb",
+			Checksum: checksum,
+			DataDir:  GinkgoT().TempDir(),
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should place RDB file as dump.rdb and AOF base", func() {
+		restored, err := Run(ctx, options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(restored).To(BeTrue())
+
+		Expect(os.ReadFile(filepath.Join(options.DataDir, "dump.rdb"))).To(Equal(rdb))
+		Expect(os.ReadFile(filepath.Join(options.DataDir, "appendonlydir", "appendonly.aof.1.base.rdb"))).To(Equal(rdb))
+		Expect(os.ReadFile(filepath.Join(options.DataDir, "appendonlydir", "appendonly.aof.manifest"))).
+			To(Equal([]byte("file appendonly.aof.1.base.rdb seq 1 type b\n")))
+		entries, err := os.ReadDir(options.DataDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(2))
+	})
+
+	It("should skip data directory holding data", func() {
+		Expect(os.WriteFile(filepath.Join(options.DataDir, "dump.rdb"), []byte("REDIS0011"), 0o600)).To(Succeed())
+
+		restored, err := Run(ctx, options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(restored).To(BeFalse())
+		Expect(os.ReadFile(filepath.Join(options.DataDir, "dump.rdb"))).To(Equal([]byte("REDIS0011")))
+	})
+
+	It("should fail on checksum mismatch", func() {
+		options.Checksum = "sha256:" + hex.EncodeToString(make([]byte, sha256.Size))
+
+		_, err := Run(ctx, options)
+		Expect(err).To(MatchError(ContainSubstring("does not match")))
+		entries, err := os.ReadDir(options.DataDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+	})
+
+	It("should fail on missing file", func() {
+		options.Location = "s3://backups/redis/missing.rdb"
+
+		_, err := Run(ctx, options)
+		Expect(err).To(MatchError(ContainSubstring("404")))
+	})
+})
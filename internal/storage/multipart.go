@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	// PartSize is the size of parts of multipart upload, S3 requires at least 5 MiB
+	PartSize = 8 << 20
+	// maxParts is the number of parts S3 accepts, objects are limited to about 78 GiB
+	maxParts = 10000
+)
+
+// Upload is a multipart upload of an object of unknown size. Data written to
+// it is uploaded in parts, so only the current part is held in memory.
+type Upload struct {
+	s3       *S3
+	ctx      context.Context
+	key      string
+	uploadID string
+	buffer   []byte
+	parts    []completedPart
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []completedPart `xml:"Part"`
+}
+
+// CreateUpload starts multipart upload of the object. Parts are uploaded
+// with ctx, the upload has to be completed or aborted.
+func (s *S3) CreateUpload(ctx context.Context, key string) (*Upload, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.objectURL(key)+"?uploads", nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := s.do(request, hashHex(nil))
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	result := struct {
+		UploadID string `xml:"UploadId"`
+	}{}
+	if err := xml.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("malformed response to multipart upload creation: %w", err)
+	}
+	if result.UploadID == "" {
+		return nil, fmt.Errorf("multipart upload creation returned no upload ID")
+	}
+	return &Upload{
+		s3:       s,
+		ctx:      ctx,
+		key:      key,
+		uploadID: result.UploadID,
+		buffer:   make([]byte, 0, PartSize),
+	}, nil
+}
+
+// Write buffers p and uploads every part filled up
+func (u *Upload) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := min(PartSize-len(u.buffer), len(p))
+		u.buffer = append(u.buffer, p[:n]...)
+		p = p[n:]
+		if len(u.buffer) == PartSize {
+			if err := u.uploadPart(); err != nil {
+				return written, err
+			}
+		}
+		written += n
+	}
+	return written, nil
+}
+
+// Complete uploads the last part and assembles the object from the parts
+func (u *Upload) Complete() error {
+	// S3 requires at least one part, empty for empty object
+	if len(u.buffer) > 0 || len(u.parts) == 0 {
+		if err := u.uploadPart(); err != nil {
+			return err
+		}
+	}
+
+	body, err := xml.Marshal(completeMultipartUpload{Parts: u.parts})
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(u.ctx, http.MethodPost, u.url(nil), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/xml")
+	response, err := u.s3.do(request, hashHex(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	// Completion may fail after 200 OK was sent, the error is in the body then
+	result := struct {
+		XMLName xml.Name
+		Error
+	}{}
+	if err := xml.NewDecoder(response.Body).Decode(&result); err != nil {
+		return fmt.Errorf("malformed response to multipart upload completion: %w", err)
+	}
+	if result.XMLName.Local == "Error" {
+		result.Error.StatusCode = response.StatusCode
+		return &result.Error
+	}
+	return nil
+}
+
+// Abort discards the upload and the parts uploaded so far
+func (u *Upload) Abort(ctx context.Context) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodDelete, u.url(nil), nil)
+	if err != nil {
+		return err
+	}
+	response, err := u.s3.do(request, hashHex(nil))
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	return nil
+}
+
+// uploadPart uploads buffered data as the next part
+func (u *Upload) uploadPart() error {
+	if len(u.parts) == maxParts {
+		return fmt.Errorf("object exceeds %d parts of %d bytes", maxParts, PartSize)
+	}
+	partNumber := len(u.parts) + 1
+	request, err := http.NewRequestWithContext(u.ctx, http.MethodPut,
+		u.url(url.Values{"partNumber": {strconv.Itoa(partNumber)}}), bytes.NewReader(u.buffer))
+	if err != nil {
+		return err
+	}
+	request.ContentLength = int64(len(u.buffer))
+	if len(u.buffer) == 0 {
+		request.Body = http.NoBody
+	}
+
+	response, err := u.s3.do(request, hashHex(u.buffer))
+	if err != nil {
+		return fmt.Errorf("failed to upload part %d: %w", partNumber, err)
+	}
+	response.Body.Close()
+
+	u.parts = append(u.parts, completedPart{PartNumber: partNumber, ETag: response.Header.Get("ETag")})
+	u.buffer = u.buffer[:0]
+	return nil
+}
+
+// url returns URL of the object with upload ID and query parameters
+func (u *Upload) url(query url.Values) string {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("uploadId", u.uploadID)
+	return u.s3.objectURL(u.key) + "?" + query.Encode()
+}
@@ -0,0 +1,274 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Keys of the secret with credentials of S3 storage
+const (
+	AccessKeyIDKey     = "AWS_ACCESS_KEY_ID"
+	SecretAccessKeyKey = "AWS_SECRET_ACCESS_KEY"
+)
+
+const (
+	signingAlgorithm = "AWS4-HMAC-SHA256"
+	amzDateFormat    = "20060102T150405Z"
+	// MaxObjectSize is the largest object S3 accepts in a single PUT
+	MaxObjectSize = 5 << 30
+	// errorBodyLimit is the number of bytes of error response read for the error message
+	errorBodyLimit = 4096
+)
+
+// Error is an error response returned by S3
+type Error struct {
+	StatusCode int
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("S3 request failed with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("S3 request failed with status %d: %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// S3Options configure access to a bucket of S3-compatible storage
+type S3Options struct {
+	// URL of the storage, e.g. 'http://minio.minio:9000'
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// S3 is a minimal client of S3-compatible object storage. Requests are signed
+// with AWS Signature Version 4, objects are addressed path-style, which both
+// AWS S3 and MinIO support.
+type S3 struct {
+	endpoint   *url.URL
+	options    S3Options
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewS3 returns client of the bucket
+func NewS3(options S3Options) (*S3, error) {
+	endpoint, err := url.Parse(options.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint: %w", err)
+	}
+	if (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q, expected http(s)://host[:port]", options.Endpoint)
+	}
+	if options.Bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is not set")
+	}
+	if options.Region == "" {
+		options.Region = "us-east-1"
+	}
+	return &S3{
+		endpoint:   endpoint,
+		options:    options,
+		httpClient: http.DefaultClient,
+		now:        time.Now,
+	}, nil
+}
+
+// ParseLocation returns bucket and key of object location, e.g. 's3://backups/redis/dump.rdb'
+func ParseLocation(location string) (string, string, error) {
+	bucket, key, found := strings.Cut(strings.TrimPrefix(location, "s3://"), "/")
+	if !strings.HasPrefix(location, "s3://") || !found || bucket == "" || key == "" {
+		return "", "", fmt.Errorf("invalid location %q, expected s3://<bucket>/<key>", location)
+	}
+	return bucket, key, nil
+}
+
+// Location returns URL of the object, e.g. 's3://backups/redis/dump.rdb'
+func (s *S3) Location(key string) string {
+	return fmt.Sprintf("s3://%s/%s", s.options.Bucket, key)
+}
+
+// PutObject uploads size bytes of body as the object. payloadHash is hex
+// encoded SHA-256 of body, S3 rejects the object if its content differs.
+func (s *S3) PutObject(ctx context.Context, key string, body io.Reader, size int64, payloadHash string) error {
+	if size > MaxObjectSize {
+		return fmt.Errorf("object of %d bytes exceeds %d bytes limit of single upload", size, int64(MaxObjectSize))
+	}
+	if size == 0 {
+		body = http.NoBody
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), body)
+	if err != nil {
+		return err
+	}
+	request.ContentLength = size
+	request.Header.Set("Content-Type", "application/octet-stream")
+
+	response, err := s.do(request, payloadHash)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	return nil
+}
+
+// GetObject downloads the object, it returns its content and size. The
+// content has to be closed.
+func (s *S3) GetObject(ctx context.Context, key string) (io.ReadCloser, int64, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	response, err := s.do(request, hashHex(nil))
+	if err != nil {
+		return nil, 0, err
+	}
+	return response.Body, response.ContentLength, nil
+}
+
+// DeleteObject removes the object, removing missing object succeeds
+func (s *S3) DeleteObject(ctx context.Context, key string) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+	response, err := s.do(request, hashHex(nil))
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	return nil
+}
+
+// objectURL returns path-style URL of the object
+func (s *S3) objectURL(key string) string {
+	objectURL := *s.endpoint
+	objectURL.Path = strings.TrimSuffix(objectURL.Path, "/") + "/" + s.options.Bucket + "/" + key
+	objectURL.RawPath = uriEncode(objectURL.Path, false)
+	return objectURL.String()
+}
+
+// do signs and sends request, responses other than 2xx are returned as Error
+func (s *S3) do(request *http.Request, payloadHash string) (*http.Response, error) {
+	s.sign(request, payloadHash, s.now())
+	response, err := s.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode/100 == 2 {
+		return response, nil
+	}
+	defer response.Body.Close()
+	s3Err := &Error{StatusCode: response.StatusCode}
+	body, _ := io.ReadAll(io.LimitReader(response.Body, errorBodyLimit))
+	_ = xml.Unmarshal(body, s3Err)
+	return nil, s3Err
+}
+
+// sign adds Authorization header of AWS Signature Version 4. Host, Range and
+// x-amz-* headers are signed.
+func (s *S3) sign(request *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format(amzDateFormat)
+	date := amzDate[:8]
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": request.URL.Host}
+	for name, values := range request.Header {
+		name = strings.ToLower(name)
+		if strings.HasPrefix(name, "x-amz-") || name == "range" {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, headers[name])
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	request.URL.RawQuery = canonicalQuery(request.URL.Query())
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		request.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, s.options.Region, "s3", "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{signingAlgorithm, amzDate, scope, hashHex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.options.SecretAccessKey), date)
+	key = hmacSHA256(key, s.options.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signingAlgorithm, s.options.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery encodes query parameters sorted by name as signed by AWS Signature Version 4
+func canonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parameters := []string{}
+	for _, name := range names {
+		values := append([]string{}, query[name]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parameters = append(parameters, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(parameters, "&")
+}
+
+// uriEncode percent-encodes every byte except unreserved characters and,
+// unless encodeSlash is set, slashes
+func uriEncode(s string, encodeSlash bool) string {
+	var encoded strings.Builder
+	for _, b := range []byte(s) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9',
+			b == '-', b == '_', b == '.', b == '~', b == '/' && !encodeSlash:
+			encoded.WriteByte(b)
+		default:
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+	return encoded.String()
+}
+
+// hashHex returns hex encoded SHA-256 of data
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,333 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+	"github.com/avekrivoy/redis-operator/internal/metadata"
+)
+
+// log is for logging in this package.
+var redislog = logf.Log.WithName("redis-resource")
+
+// imageTagPattern is the grammar of Docker image tags
+var imageTagPattern = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+
+// configDirectivePattern is the grammar of redis.conf directive names
+var configDirectivePattern = regexp.MustCompile(`^[a-zA-Z0-9-]+$`)
+
+// defaultStorageClassAnnotation marks the StorageClass used for PVCs without storage class
+const defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+
+// SetupRedisWebhookWithManager registers the webhook for Redis in the manager.
+func SetupRedisWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&cachev1alpha1.Redis{}).
+		WithValidator(&RedisCustomValidator{}).
+		WithDefaulter(&RedisCustomDefaulter{Client: mgr.GetClient()}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-cache-assignment-yazio-com-v1alpha1-redis,mutating=true,failurePolicy=ignore,sideEffects=None,groups=cache.assignment.yazio.com,resources=redis,verbs=create,versions=v1alpha1,name=mredis.kb.io,admissionReviewVersions=v1
+//+kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+
+// RedisCustomDefaulter sets defaults derived from the cluster state, which
+// can not be expressed by CRD defaults. Fields set in the spec are kept.
+// The webhook is ignored when unavailable: unset fields fall back to
+// Kubernetes defaults, e.g. PVCs without storage class use the default one.
+type RedisCustomDefaulter struct {
+	Client client.Client
+}
+
+var _ webhook.CustomDefaulter = &RedisCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the type Redis.
+func (d *RedisCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	redis, ok := obj.(*cachev1alpha1.Redis)
+	if !ok {
+		return fmt.Errorf("expected a Redis object but got %T", obj)
+	}
+	redislog.Info("Defaulting for Redis", "name", redis.GetName())
+
+	if redis.Spec.Common.StorageClass == "" {
+		storageClass, err := d.defaultStorageClass(ctx)
+		if err != nil {
+			return err
+		}
+		redis.Spec.Common.StorageClass = storageClass
+	}
+	return nil
+}
+
+// defaultStorageClass returns name of the default StorageClass of the cluster,
+// empty if there is none
+func (d *RedisCustomDefaulter) defaultStorageClass(ctx context.Context) (string, error) {
+	storageClasses := &storagev1.StorageClassList{}
+	if err := d.Client.List(ctx, storageClasses); err != nil {
+		return "", err
+	}
+	for _, storageClass := range storageClasses.Items {
+		if storageClass.Annotations[defaultStorageClassAnnotation] == "true" {
+			return storageClass.Name, nil
+		}
+	}
+	return "", nil
+}
+
+//+kubebuilder:webhook:path=/validate-cache-assignment-yazio-com-v1alpha1-redis,mutating=false,failurePolicy=fail,sideEffects=None,groups=cache.assignment.yazio.com,resources=redis,verbs=create;update,versions=v1alpha1,name=vredis.kb.io,admissionReviewVersions=v1
+
+// RedisCustomValidator rejects Redis specs the operator can not deploy
+type RedisCustomValidator struct{}
+
+var _ webhook.CustomValidator = &RedisCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type Redis.
+func (v *RedisCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	redis, ok := obj.(*cachev1alpha1.Redis)
+	if !ok {
+		return nil, fmt.Errorf("expected a Redis object but got %T", obj)
+	}
+	redislog.Info("Validation for Redis upon creation", "name", redis.GetName())
+
+	return nil, toInvalid(redis, validateSpec(&redis.Spec))
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type Redis.
+func (v *RedisCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	redis, ok := newObj.(*cachev1alpha1.Redis)
+	if !ok {
+		return nil, fmt.Errorf("expected a Redis object for the newObj but got %T", newObj)
+	}
+	old, ok := oldObj.(*cachev1alpha1.Redis)
+	if !ok {
+		return nil, fmt.Errorf("expected a Redis object for the oldObj but got %T", oldObj)
+	}
+	redislog.Info("Validation for Redis upon update", "name", redis.GetName())
+
+	errs := validateSpec(&redis.Spec)
+	errs = append(errs, validateSpecUpdate(&old.Spec, &redis.Spec)...)
+	errs = append(errs, validateMigrationUpdate(&old.Status, &old.Spec, &redis.Spec)...)
+	return nil, toInvalid(redis, errs)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type Redis.
+func (v *RedisCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func toInvalid(redis *cachev1alpha1.Redis, errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(
+		schema.GroupKind{Group: cachev1alpha1.GroupVersion.Group, Kind: "Redis"},
+		redis.Name, errs)
+}
+
+// validateSpec checks the spec on its own
+func validateSpec(spec *cachev1alpha1.RedisSpec) field.ErrorList {
+	errs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+
+	kinds := []string{cachev1alpha1.KindDeployment, cachev1alpha1.KindStatefulSet}
+	if !isOneOf(spec.Master.Kind, kinds) {
+		errs = append(errs, field.NotSupported(specPath.Child("master", "kind"), spec.Master.Kind, kinds))
+	}
+	if !isOneOf(spec.Replica.Kind, kinds) {
+		errs = append(errs, field.NotSupported(specPath.Child("replica", "kind"), spec.Replica.Kind, kinds))
+	}
+
+	if spec.Mode != cachev1alpha1.ModeCluster {
+		if spec.Master.Count < 0 {
+			errs = append(errs, field.Invalid(specPath.Child("master", "count"), spec.Master.Count, "must not be negative"))
+		}
+		if spec.Replica.Count < 0 {
+			errs = append(errs, field.Invalid(specPath.Child("replica", "count"), spec.Replica.Count, "must not be negative"))
+		}
+		if spec.Replica.Count > 0 && spec.Master.Count < 1 {
+			errs = append(errs, field.Invalid(specPath.Child("replica", "count"), spec.Replica.Count,
+				"replicas require at least one master, set master.count to 1 or more"))
+		}
+	}
+
+	sentinelPath := specPath.Child("sentinel")
+	if spec.Sentinel.Enabled {
+		if spec.Mode == cachev1alpha1.ModeCluster {
+			errs = append(errs, field.Forbidden(sentinelPath.Child("enabled"), "Sentinel can not be used in cluster mode"))
+		}
+		if spec.Sentinel.Quorum > spec.Sentinel.Count {
+			errs = append(errs, field.Invalid(sentinelPath.Child("quorum"), spec.Sentinel.Quorum,
+				fmt.Sprintf("must not be greater than sentinel.count (%d)", spec.Sentinel.Count)))
+		}
+	}
+
+	auth := spec.Common.Auth
+	if !auth.IsEnabled() && auth.ExistingSecret != "" {
+		errs = append(errs, field.Forbidden(specPath.Child("common", "auth", "existingSecret"),
+			"existingSecret can not be used when auth.enabled is false"))
+	}
+	if auth.RotationPeriod != nil {
+		rotationPath := specPath.Child("common", "auth", "rotationPeriod")
+		if auth.RotationPeriod.Duration < time.Hour {
+			errs = append(errs, field.Invalid(rotationPath, auth.RotationPeriod.Duration.String(), "must be at least 1h"))
+		}
+		if !auth.IsEnabled() || auth.ExistingSecret != "" {
+			errs = append(errs, field.Forbidden(rotationPath, "only the password generated by the operator can be rotated"))
+		}
+	}
+
+	tlsSpec := spec.Common.TLS
+	tlsPath := specPath.Child("common", "tls")
+	if !tlsSpec.Enabled && tlsSpec.ExistingSecret != "" {
+		errs = append(errs, field.Forbidden(tlsPath.Child("existingSecret"),
+			"existingSecret can not be used when tls.enabled is false"))
+	}
+	if tlsSpec.GetCertificateDuration() < time.Hour {
+		errs = append(errs, field.Invalid(tlsPath.Child("certificateDuration"), tlsSpec.GetCertificateDuration().String(), "must be at least 1h"))
+	}
+	if tlsSpec.GetRenewBefore() >= tlsSpec.GetCertificateDuration() {
+		errs = append(errs, field.Invalid(tlsPath.Child("renewBefore"), tlsSpec.GetRenewBefore().String(),
+			"must be shorter than certificateDuration"))
+	}
+
+	errs = append(errs, validatePodDisruptionBudget(specPath.Child("common", "podDisruptionBudget"), &spec.Common.PodDisruptionBudget)...)
+	errs = append(errs, validatePodDisruptionBudget(specPath.Child("master", "podDisruptionBudget"), &spec.Master.PodDisruptionBudget)...)
+	errs = append(errs, validatePodDisruptionBudget(specPath.Child("replica", "podDisruptionBudget"), &spec.Replica.PodDisruptionBudget)...)
+
+	errs = append(errs, validateConfig(specPath.Child("common", "config"), spec.Common.Config)...)
+	errs = append(errs, validateConfig(specPath.Child("master", "config"), spec.Master.Config)...)
+	errs = append(errs, validateConfig(specPath.Child("replica", "config"), spec.Replica.Config)...)
+	errs = append(errs, validateConfig(specPath.Child("cluster", "config"), spec.Cluster.Config)...)
+
+	image := spec.Common.Image
+	imagePath := specPath.Child("common", "image")
+	if image.ImageRepository == "" {
+		errs = append(errs, field.Required(imagePath.Child("imageRegistry"), "image repository must be set"))
+	}
+	if !imageTagPattern.MatchString(image.ImageTag) {
+		errs = append(errs, field.Invalid(imagePath.Child("imageTag"), image.ImageTag,
+			"must start with a letter, digit or underscore and contain up to 128 letters, digits, '_', '.' or '-'"))
+	}
+
+	return errs
+}
+
+// validateSpecUpdate checks changes that can not be applied to running Redis
+func validateSpecUpdate(old *cachev1alpha1.RedisSpec, spec *cachev1alpha1.RedisSpec) field.ErrorList {
+	errs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+
+	errs = append(errs, validateStorageSize(specPath.Child("master", "persistence", "size"),
+		old.Master.Persistence.Size, spec.Master.Persistence.Size)...)
+	errs = append(errs, validateStorageSize(specPath.Child("replica", "persistence", "size"),
+		old.Replica.Persistence.Size, spec.Replica.Persistence.Size)...)
+	errs = append(errs, validateStorageSize(specPath.Child("cluster", "persistence", "size"),
+		old.Cluster.Persistence.Size, spec.Cluster.Persistence.Size)...)
+	return errs
+}
+
+// validateMigrationUpdate forbids changing workload kind of a component while
+// its migration is in progress, the reverted kind would be the one removed
+func validateMigrationUpdate(status *cachev1alpha1.RedisStatus, old *cachev1alpha1.RedisSpec, spec *cachev1alpha1.RedisSpec) field.ErrorList {
+	errs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+
+	components := []struct {
+		name    string
+		path    *field.Path
+		oldKind string
+		kind    string
+	}{
+		{metadata.RedisMasterComponent(), specPath.Child("master", "kind"), old.Master.Kind, spec.Master.Kind},
+		{metadata.RedisReplicaComponent(), specPath.Child("replica", "kind"), old.Replica.Kind, spec.Replica.Kind},
+	}
+	for _, component := range components {
+		if component.oldKind == component.kind {
+			continue
+		}
+		if migration := status.ActiveMigration(component.name); migration != nil {
+			errs = append(errs, field.Forbidden(component.path,
+				fmt.Sprintf("kind can not be changed while migration from %s to %s is in phase %s",
+					migration.From, migration.To, migration.Phase)))
+		}
+	}
+	return errs
+}
+
+// validatePodDisruptionBudget allows only one of minAvailable and maxUnavailable
+func validatePodDisruptionBudget(path *field.Path, pdb *cachev1alpha1.RedisPodDisruptionBudgetSpec) field.ErrorList {
+	if pdb.MinAvailable != nil && pdb.MaxUnavailable != nil {
+		return field.ErrorList{field.Forbidden(path.Child("maxUnavailable"), "minAvailable and maxUnavailable are mutually exclusive")}
+	}
+	return nil
+}
+
+// validateConfig forbids redis.conf directives set by the operator
+func validateConfig(path *field.Path, config cachev1alpha1.RedisConfig) field.ErrorList {
+	errs := field.ErrorList{}
+	names := make([]string, 0, len(config))
+	for name := range config {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !configDirectivePattern.MatchString(name) {
+			errs = append(errs, field.Invalid(path.Key(name), name, "must be a redis.conf directive name"))
+		} else if cachev1alpha1.IsOperatorConfigDirective(name) {
+			errs = append(errs, field.Forbidden(path.Key(name), "directive is managed by the operator"))
+		} else if strings.ContainsAny(config[name], "\n\r") {
+			errs = append(errs, field.Invalid(path.Key(name), config[name], "must not contain line breaks"))
+		}
+	}
+	return errs
+}
+
+// validateStorageSize forbids shrinking volumes, Kubernetes can only expand PVCs
+func validateStorageSize(path *field.Path, old resource.Quantity, size resource.Quantity) field.ErrorList {
+	if old.IsZero() || size.Cmp(old) >= 0 {
+		return nil
+	}
+	return field.ErrorList{field.Forbidden(path,
+		fmt.Sprintf("storage can not be shrunk from %s to %s", old.String(), size.String()))}
+}
+
+func isOneOf(value string, allowed []string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
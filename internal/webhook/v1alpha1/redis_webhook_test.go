@@ -0,0 +1,232 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	cachev1alpha1 "github.com/avekrivoy/redis-operator/api/v1alpha1"
+)
+
+// newRedis returns Redis with the same values CRD defaulting would set
+func newRedis() *cachev1alpha1.Redis {
+	return &cachev1alpha1.Redis{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-redis", Namespace: "default"},
+		Spec: cachev1alpha1.RedisSpec{
+			Mode: cachev1alpha1.ModeReplication,
+			Common: cachev1alpha1.RedisCommonSpec{
+				Image: cachev1alpha1.RedisImageSpec{
+					ImageRepository: "bitnami/redis",
+					ImageTag:        "7.2.5",
+				},
+				Auth: cachev1alpha1.RedisAuthSpec{Enabled: ptr.To(true)},
+			},
+			Master: cachev1alpha1.RedisMasterSpec{
+				Count: 1,
+				Kind:  cachev1alpha1.KindDeployment,
+				Persistence: cachev1alpha1.RedisPersistenceSpec{
+					Size: resource.MustParse("8Gi"),
+				},
+			},
+			Replica: cachev1alpha1.RedisReplicaSpec{
+				Kind: cachev1alpha1.KindDeployment,
+			},
+			Sentinel: cachev1alpha1.RedisSentinelSpec{
+				Count:  3,
+				Quorum: 2,
+			},
+		},
+	}
+}
+
+var _ = Describe("Redis validating webhook", func() {
+	var validator *RedisCustomValidator
+	var redis *cachev1alpha1.Redis
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		validator = &RedisCustomValidator{}
+		redis = newRedis()
+	})
+
+	It("should accept a valid spec", func() {
+		_, err := validator.ValidateCreate(ctx, redis)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject unknown workload kind", func() {
+		redis.Spec.Replica.Kind = "daemonset"
+		_, err := validator.ValidateCreate(ctx, redis)
+		Expect(err).To(MatchError(ContainSubstring("spec.replica.kind")))
+	})
+
+	It("should reject replicas without a master", func() {
+		redis.Spec.Master.Count = 0
+		redis.Spec.Replica.Count = 2
+		_, err := validator.ValidateCreate(ctx, redis)
+		Expect(err).To(MatchError(ContainSubstring("replicas require at least one master")))
+	})
+
+	It("should reject existing secret with disabled auth", func() {
+		redis.Spec.Common.Auth.Enabled = ptr.To(false)
+		redis.Spec.Common.Auth.ExistingSecret = "redis-password"
+		_, err := validator.ValidateCreate(ctx, redis)
+		Expect(err).To(MatchError(ContainSubstring("spec.common.auth.existingSecret")))
+	})
+
+	It("should reject rotation of existing secret", func() {
+		redis.Spec.Common.Auth.ExistingSecret = "redis-password"
+		redis.Spec.Common.Auth.RotationPeriod = &metav1.Duration{Duration: 720 * time.Hour}
+		_, err := validator.ValidateCreate(ctx, redis)
+		Expect(err).To(MatchError(ContainSubstring("spec.common.auth.rotationPeriod")))
+	})
+
+	It("should reject too short rotation period", func() {
+		redis.Spec.Common.Auth.RotationPeriod = &metav1.Duration{Duration: time.Minute}
+		_, err := validator.ValidateCreate(ctx, redis)
+		Expect(err).To(MatchError(ContainSubstring("must be at least 1h")))
+	})
+
+	It("should reject TLS secret when TLS is disabled", func() {
+		redis.Spec.Common.TLS.ExistingSecret = "redis-tls"
+		_, err := validator.ValidateCreate(ctx, redis)
+		Expect(err).To(MatchError(ContainSubstring("spec.common.tls.existingSecret")))
+	})
+
+	It("should reject certificate renewed before it is issued", func() {
+		redis.Spec.Common.TLS.Enabled = true
+		redis.Spec.Common.TLS.CertificateDuration = &metav1.Duration{Duration: 24 * time.Hour}
+		_, err := validator.ValidateCreate(ctx, redis)
+		Expect(err).To(MatchError(ContainSubstring("spec.common.tls.renewBefore")))
+	})
+
+	It("should reject config directives managed by the operator", func() {
+		redis.Spec.Common.Config = cachev1alpha1.RedisConfig{"maxmemory-policy": "allkeys-lru"}
+		redis.Spec.Replica.Config = cachev1alpha1.RedisConfig{"ReplicaOf": "10.0.0.1 6379"}
+		_, err := validator.ValidateCreate(ctx, redis)
+		Expect(err).To(MatchError(ContainSubstring("spec.replica.config[ReplicaOf]")))
+		Expect(err).NotTo(MatchError(ContainSubstring("spec.common.config")))
+	})
+
+	It("should reject PodDisruptionBudget with both minAvailable and maxUnavailable", func() {
+		minAvailable := intstr.FromInt32(1)
+		maxUnavailable := intstr.FromString("50%")
+		redis.Spec.Replica.PodDisruptionBudget.MinAvailable = &minAvailable
+		redis.Spec.Replica.PodDisruptionBudget.MaxUnavailable = &maxUnavailable
+		_, err := validator.ValidateCreate(ctx, redis)
+		Expect(err).To(MatchError(ContainSubstring("spec.replica.podDisruptionBudget.maxUnavailable")))
+	})
+
+	It("should reject malformed image tag", func() {
+		redis.Spec.Common.Image.ImageTag = "7.2.5:latest"
+		_, err := validator.ValidateCreate(ctx, redis)
+		Expect(err).To(MatchError(ContainSubstring("spec.common.image.imageTag")))
+	})
+
+	It("should reject Sentinel quorum greater than Sentinel count", func() {
+		redis.Spec.Sentinel.Enabled = true
+		redis.Spec.Sentinel.Quorum = 4
+		_, err := validator.ValidateCreate(ctx, redis)
+		Expect(err).To(MatchError(ContainSubstring("spec.sentinel.quorum")))
+	})
+
+	It("should reject shrinking storage", func() {
+		updated := redis.DeepCopy()
+		updated.Spec.Master.Persistence.Size = resource.MustParse("4Gi")
+		_, err := validator.ValidateUpdate(ctx, redis, updated)
+		Expect(err).To(MatchError(ContainSubstring("storage can not be shrunk from 8Gi to 4Gi")))
+
+		updated.Spec.Master.Persistence.Size = resource.MustParse("16Gi")
+		_, err = validator.ValidateUpdate(ctx, redis, updated)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject reverting workload kind during migration", func() {
+		redis.Spec.Master.Kind = cachev1alpha1.KindStatefulSet
+		redis.Status.Migrations = []cachev1alpha1.RedisMigrationStatus{{
+			Component: "redis-master",
+			From:      cachev1alpha1.KindDeployment,
+			To:        cachev1alpha1.KindStatefulSet,
+			Phase:     cachev1alpha1.MigrationPhaseSwitchingService,
+		}}
+		updated := redis.DeepCopy()
+		updated.Spec.Master.Kind = cachev1alpha1.KindDeployment
+		_, err := validator.ValidateUpdate(ctx, redis, updated)
+		Expect(err).To(MatchError(ContainSubstring("spec.master.kind")))
+
+		redis.Status.Migrations[0].Phase = cachev1alpha1.MigrationPhaseCompleted
+		_, err = validator.ValidateUpdate(ctx, redis, updated)
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
+
+var _ = Describe("Redis defaulting webhook", func() {
+	ctx := context.Background()
+
+	newDefaulter := func(storageClasses ...*storagev1.StorageClass) *RedisCustomDefaulter {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		builder := fake.NewClientBuilder().WithScheme(scheme)
+		for _, storageClass := range storageClasses {
+			builder = builder.WithObjects(storageClass)
+		}
+		return &RedisCustomDefaulter{Client: builder.Build()}
+	}
+
+	storageClass := func(name string, isDefault bool) *storagev1.StorageClass {
+		storageClass := &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if isDefault {
+			storageClass.Annotations = map[string]string{defaultStorageClassAnnotation: "true"}
+		}
+		return storageClass
+	}
+
+	It("should default storage class to the cluster default", func() {
+		defaulter := newDefaulter(storageClass("standard", false), storageClass("fast-ssd", true))
+		redis := newRedis()
+		Expect(defaulter.Default(ctx, redis)).To(Succeed())
+		Expect(redis.Spec.Common.StorageClass).To(Equal("fast-ssd"))
+	})
+
+	It("should keep storage class set in spec", func() {
+		defaulter := newDefaulter(storageClass("fast-ssd", true))
+		redis := newRedis()
+		redis.Spec.Common.StorageClass = "standard"
+		Expect(defaulter.Default(ctx, redis)).To(Succeed())
+		Expect(redis.Spec.Common.StorageClass).To(Equal("standard"))
+	})
+
+	It("should leave storage class empty without default StorageClass", func() {
+		defaulter := newDefaulter(storageClass("standard", false))
+		redis := newRedis()
+		Expect(defaulter.Default(ctx, redis)).To(Succeed())
+		Expect(redis.Spec.Common.StorageClass).To(BeEmpty())
+	})
+})